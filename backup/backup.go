@@ -0,0 +1,138 @@
+package backup
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rocketbang/spotify-controller/config"
+)
+
+// Snapshot is the full ordered track list of a playlist at a point in time
+type Snapshot struct {
+	SnapshotID   string    `json:"snapshotId"`
+	PlaylistName string    `json:"playlistName"`
+	CreatedAt    time.Time `json:"createdAt"`
+	Reason       string    `json:"reason"`
+	URIs         []string  `json:"uris"`
+}
+
+// MaxSnapshots is how many snapshots are kept for each playlist, the oldest are dropped first
+const MaxSnapshots = 50
+
+func playlistFile(playlistID string) (string, error) {
+	dir, err := config.DataPath("backups")
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, playlistID+".json"), nil
+}
+
+// History will return the stored snapshots of a playlist, oldest first
+func History(playlistID string) ([]*Snapshot, error) {
+	path, err := playlistFile(playlistID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return []*Snapshot{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots := make([]*Snapshot, 0)
+	err = json.Unmarshal(data, &snapshots)
+	if err != nil {
+		return nil, errors.New("Could not decode backups for " + playlistID)
+	}
+	return snapshots, nil
+}
+
+// Has will return true if the given snapshot of the playlist is already stored
+func Has(playlistID string, snapshotID string) bool {
+	snapshots, err := History(playlistID)
+	if err != nil {
+		return false
+	}
+	for _, snapshot := range snapshots {
+		if snapshot.SnapshotID == snapshotID {
+			return true
+		}
+	}
+	return false
+}
+
+// Save will store the snapshot for the playlist, snapshots that are already stored are ignored
+//
+// Only the latest MaxSnapshots are kept
+func Save(playlistID string, snapshot *Snapshot) error {
+	snapshots, err := History(playlistID)
+	if err != nil {
+		return err
+	}
+
+	for _, existing := range snapshots {
+		if existing.SnapshotID == snapshot.SnapshotID {
+			return nil
+		}
+	}
+	snapshots = append(snapshots, snapshot)
+	if len(snapshots) > MaxSnapshots {
+		snapshots = snapshots[len(snapshots)-MaxSnapshots:]
+	}
+
+	data, err := json.MarshalIndent(snapshots, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	path, err := playlistFile(playlistID)
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so a failed write can't corrupt the existing backups
+	tmpPath := path + ".tmp"
+	err = ioutil.WriteFile(tmpPath, data, 0600)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// Find will return the snapshot matching the given history number (starting at 1) or snapshot ID prefix
+func Find(playlistID string, search string) (*Snapshot, error) {
+	snapshots, err := History(playlistID)
+	if err != nil {
+		return nil, err
+	}
+
+	if num, err := strconv.Atoi(search); err == nil {
+		if num < 1 || num > len(snapshots) {
+			return nil, errors.New("Backup number is out of bounds")
+		}
+		return snapshots[num-1], nil
+	}
+
+	var found *Snapshot
+	for _, snapshot := range snapshots {
+		if strings.HasPrefix(snapshot.SnapshotID, search) {
+			if found != nil {
+				return nil, errors.New("More than one backup matches " + search)
+			}
+			found = snapshot
+		}
+	}
+	if found == nil {
+		return nil, errors.New("No backup matches " + search)
+	}
+	return found, nil
+}
@@ -0,0 +1,240 @@
+package command
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rocketbang/spotify-controller/history"
+	"github.com/rocketbang/spotify-controller/shuffle"
+	"github.com/rocketbang/spotify-controller/spotify"
+)
+
+type shuffleSource struct {
+	Name   string
+	Rounds [][]shuffle.Track
+}
+
+func analyzeShuffle(args string) {
+	flags, rest := splitFlags(args)
+
+	rounds, err := intFlag(flags, "rounds", 20)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+	depth, err := intFlag(flags, "depth", 40)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+	strategy, random, err := getShuffleStrategy(flags)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+	mode := flags["mode"]
+	if mode == "" {
+		mode = "uniform"
+	}
+
+	playlist := findPlaylist(rest)
+	if playlist == nil {
+		return
+	}
+
+	items := spotify.GetTracksInPlaylist(playlist.ID)
+	if items == nil {
+		fmt.Println("Could not get playlist tracks")
+		return
+	}
+
+	tracks := make([]shuffle.Track, 0, len(items))
+	tracksByURI := make(map[string]shuffle.Track, len(items))
+	for _, track := range toShuffleTracks(items) {
+		if track.URI == "" {
+			continue
+		}
+		tracks = append(tracks, track)
+		tracksByURI[track.URI] = track
+	}
+	if len(tracks) == 0 {
+		fmt.Printf("%s has no tracks\n", playlist.Name)
+		return
+	}
+	if depth > len(tracks) {
+		depth = len(tracks)
+	}
+
+	targetURIs := findTargetTracks(tracks, flags["track"])
+
+	sources := make([]*shuffleSource, 0)
+
+	if flags["local-only"] == "" {
+		// Every track played while reading the shuffle would be recorded as a quick skip
+		resume := history.PauseRecording()
+		defer resume()
+
+		spotifySource := &shuffleSource{Name: "spotify"}
+		for round := 0; round < rounds; round++ {
+			fmt.Printf("Reading spotify shuffle round %d of %d\n", round+1, rounds)
+			order := readSpotifyShuffle(playlist.URI, depth, flags["skip"] != "", tracksByURI)
+			if len(order) == 0 {
+				fmt.Println("Could not read the spotify shuffle order")
+				break
+			}
+			if len(order) < depth && round == 0 {
+				fmt.Printf("Spotify only returned %d queued tracks, use --skip to read further by skipping tracks\n", len(order))
+			}
+			spotifySource.Rounds = append(spotifySource.Rounds, order)
+		}
+		if len(spotifySource.Rounds) > 0 {
+			sources = append(sources, spotifySource)
+		}
+	}
+
+	localSource := &shuffleSource{Name: mode}
+	for round := 0; round < rounds; round++ {
+		order := strategy(tracks, random)
+		shuffled := make([]shuffle.Track, 0, depth)
+		for _, index := range order[:depth] {
+			shuffled = append(shuffled, tracks[index])
+		}
+		localSource.Rounds = append(localSource.Rounds, shuffled)
+	}
+	sources = append(sources, localSource)
+
+	for _, source := range sources {
+		printShuffleReport(source.Name, shuffle.Analyse(tracks, source.Rounds, depth, targetURIs))
+	}
+
+	if path := flags["csv"]; path != "" {
+		err := writeShuffleCSV(path, sources)
+		if err != nil {
+			fmt.Printf("Could not write %s: %s\n", path, err.Error())
+			return
+		}
+		fmt.Printf("Wrote rounds to %s\n", path)
+	}
+}
+
+// findTargetTracks finds the URIs of the comma separated track names or URIs
+func findTargetTracks(tracks []shuffle.Track, targets string) []string {
+	uris := make([]string, 0)
+	if targets == "" {
+		return uris
+	}
+
+	for _, target := range strings.Split(targets, ",") {
+		target = strings.TrimSpace(target)
+		lowerTarget := strings.ToLower(target)
+		found := false
+		for _, track := range tracks {
+			if track.URI == target || strings.ToLower(track.Name) == lowerTarget {
+				uris = append(uris, track.URI)
+				found = true
+				break
+			}
+		}
+		if !found {
+			fmt.Printf("Could not find %s in the playlist\n", target)
+		}
+	}
+	return uris
+}
+
+// readSpotifyShuffle starts the playlist with shuffle on and returns the order spotify plays it in
+//
+// By default the order is read from the queue, skip reads it by skipping through the tracks instead
+func readSpotifyShuffle(playlistURI string, depth int, skip bool, tracksByURI map[string]shuffle.Track) []shuffle.Track {
+	order := make([]shuffle.Track, 0, depth)
+
+	if skip {
+		abort := make(chan struct{})
+		for song := range getNextNSongs(depth, playlistURI, abort) {
+			order = append(order, lookupShuffleTrack(tracksByURI, song.URI, song.Name, song.ArtistName))
+		}
+		return order
+	}
+
+	spotify.SetShuffle(true)
+	spotify.PlayPlaylist(playlistURI)
+	time.Sleep(1 * time.Second)
+
+	for _, track := range spotify.GetQueue() {
+		if len(order) == depth {
+			break
+		}
+		shuffleTrack, ok := tracksByURI[track.URI]
+		if !ok {
+			shuffleTrack = toShuffleTrack(track)
+		}
+		order = append(order, shuffleTrack)
+	}
+	return order
+}
+
+func lookupShuffleTrack(tracksByURI map[string]shuffle.Track, uri string, name string, artist string) shuffle.Track {
+	if track, ok := tracksByURI[uri]; ok {
+		return track
+	}
+	return shuffle.Track{URI: uri, Name: name, Artist: artist}
+}
+
+func printShuffleReport(name string, report *shuffle.Report) {
+	fmt.Printf("\n%s shuffle (%d rounds, first %d of %d tracks)\n", name, report.Rounds, report.Depth, report.Tracks)
+	fmt.Printf("Coverage chi-square: %.1f (df %d), p = %.3f\n", report.Coverage.Statistic, report.Coverage.DegreesOfFreedom, report.Coverage.PValue)
+	fmt.Printf("Artist repeats: %.1f%% (uniform %.1f%%)\n", report.ArtistRepeatRate*100, report.ExpectedArtistRepeatRate*100)
+	fmt.Printf("Recently added bias: %.2f (0.5 is unbiased, higher favours newer tracks)\n", report.RecencyScore)
+
+	for _, target := range report.Targets {
+		fmt.Printf("\n%s\n", target.Track.Name)
+		printHistogram(target.Positions)
+		fmt.Printf("Not in first %d: %d\n", report.Depth, target.Missing)
+		fmt.Printf("Chi-square: %.1f (df %d), p = %.3f\n", target.ChiSquare.Statistic, target.ChiSquare.DegreesOfFreedom, target.ChiSquare.PValue)
+	}
+}
+
+// printHistogram prints the position counts in buckets of 5 positions
+func printHistogram(positions []int) {
+	bucketSize := 5
+	for start := 0; start < len(positions); start += bucketSize {
+		end := start + bucketSize
+		if end > len(positions) {
+			end = len(positions)
+		}
+
+		count := 0
+		for _, positionCount := range positions[start:end] {
+			count += positionCount
+		}
+		fmt.Printf("%3d-%-3d %s %d\n", start+1, end, strings.Repeat("#", count), count)
+	}
+}
+
+func writeShuffleCSV(path string, sources []*shuffleSource) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	writer.Write([]string{"source", "round", "position", "uri", "name", "artist", "added_at"})
+	for _, source := range sources {
+		for round, tracks := range source.Rounds {
+			for position, track := range tracks {
+				addedAt := ""
+				if !track.AddedAt.IsZero() {
+					addedAt = track.AddedAt.Format(time.RFC3339)
+				}
+				writer.Write([]string{source.Name, strconv.Itoa(round + 1), strconv.Itoa(position + 1), track.URI, track.Name, track.Artist, addedAt})
+			}
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,100 @@
+package command
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// splitFlags separates "--name" and "--name=value" flags from the rest of the command args
+//
+// Flags without a value are set to "true". Values can be quoted to include spaces
+func splitFlags(args string) (map[string]string, string) {
+	flags := make(map[string]string)
+	rest := make([]string, 0)
+
+	for _, field := range splitFields(args) {
+		if !startsWith(field, "--") || len(field) == 2 {
+			rest = append(rest, field)
+			continue
+		}
+
+		name := field[2:]
+		value := "true"
+		if equals := strings.Index(name, "="); equals >= 0 {
+			value = strings.Replace(name[equals+1:], "\"", "", -1)
+			name = name[:equals]
+		}
+		flags[name] = value
+	}
+
+	return flags, strings.Join(rest, " ")
+}
+
+// intFlag reads a whole number flag, returning auto if the flag isn't set
+func intFlag(flags map[string]string, name string, auto int) (int, error) {
+	value, ok := flags[name]
+	if !ok {
+		return auto, nil
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil || number < 1 {
+		return 0, errors.New("--" + name + " must be a positive number")
+	}
+	return number, nil
+}
+
+// splitQuoted splits the text on spaces, keeping text inside double quotes together
+func splitQuoted(text string) []string {
+	fields := splitFields(text)
+	for i := range fields {
+		fields[i] = strings.Replace(fields[i], "\"", "", -1)
+	}
+	return fields
+}
+
+// splitQuotedList splits the text on commas, keeping text inside double quotes together, e.g. a, "b, c"
+//
+// Spaces around each item and the quotes are removed
+func splitQuotedList(text string) []string {
+	items := make([]string, 0)
+	for _, item := range splitOutsideQuotes(text, ',') {
+		item = strings.Replace(strings.TrimSpace(item), "\"", "", -1)
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// splitFields splits the text on spaces, keeping text inside double quotes together
+// with the quotes left in place
+func splitFields(text string) []string {
+	return splitOutsideQuotes(text, ' ')
+}
+
+// splitOutsideQuotes splits the text on the separator when it isn't inside double quotes, empty fields are dropped
+func splitOutsideQuotes(text string, separator rune) []string {
+	fields := make([]string, 0)
+	current := ""
+	inQuotes := false
+
+	for _, r := range text {
+		if r == '"' {
+			inQuotes = !inQuotes
+		}
+		if r == separator && !inQuotes {
+			if current != "" {
+				fields = append(fields, current)
+			}
+			current = ""
+			continue
+		}
+		current += string(r)
+	}
+	if current != "" {
+		fields = append(fields, current)
+	}
+	return fields
+}
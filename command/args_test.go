@@ -0,0 +1,112 @@
+package command
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitFields(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"", []string{}},
+		{"   ", []string{}},
+		{"one", []string{"one"}},
+		{"one  two ", []string{"one", "two"}},
+		{`"road trip" chill`, []string{`"road trip"`, "chill"}},
+		{`--name="my mix" rest`, []string{`--name="my mix"`, "rest"}},
+		{`"unclosed quote here`, []string{`"unclosed quote here`}},
+		{`a"b c"d`, []string{`a"b c"d`}},
+	}
+
+	for _, test := range tests {
+		if got := splitFields(test.text); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("splitFields(%q) = %q, want %q", test.text, got, test.want)
+		}
+	}
+}
+
+func TestSplitQuoted(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"", []string{}},
+		{`"road trip" chill`, []string{"road trip", "chill"}},
+		{`a "" b`, []string{"a", "", "b"}},
+	}
+
+	for _, test := range tests {
+		if got := splitQuoted(test.text); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("splitQuoted(%q) = %q, want %q", test.text, got, test.want)
+		}
+	}
+}
+
+func TestSplitQuotedList(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"", []string{}},
+		{"one", []string{"one"}},
+		{"one, two ,three", []string{"one", "two", "three"}},
+		{`chill, "rock, roll", jazz`, []string{"chill", "rock, roll", "jazz"}},
+		{"one,,two, ", []string{"one", "two"}},
+		{"road trip, chill", []string{"road trip", "chill"}},
+	}
+
+	for _, test := range tests {
+		if got := splitQuotedList(test.text); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("splitQuotedList(%q) = %q, want %q", test.text, got, test.want)
+		}
+	}
+}
+
+func TestSplitFlags(t *testing.T) {
+	tests := []struct {
+		args      string
+		wantFlags map[string]string
+		wantRest  string
+	}{
+		{"", map[string]string{}, ""},
+		{"road trip", map[string]string{}, "road trip"},
+		{"--dry-run road trip", map[string]string{"dry-run": "true"}, "road trip"},
+		{"road --limit=20 trip", map[string]string{"limit": "20"}, "road trip"},
+		{`--name="my mix" --by=artist chill`, map[string]string{"name": "my mix", "by": "artist"}, "chill"},
+		{`"road trip" --seed=42`, map[string]string{"seed": "42"}, `"road trip"`},
+		{"-- a -b", map[string]string{}, "-- a -b"},
+		{"--limit=5 --limit=10", map[string]string{"limit": "10"}, ""},
+		{"--empty=", map[string]string{"empty": ""}, ""},
+	}
+
+	for _, test := range tests {
+		flags, rest := splitFlags(test.args)
+		if !reflect.DeepEqual(flags, test.wantFlags) || rest != test.wantRest {
+			t.Errorf("splitFlags(%q) = %v, %q, want %v, %q", test.args, flags, rest, test.wantFlags, test.wantRest)
+		}
+	}
+}
+
+func TestIntFlag(t *testing.T) {
+	tests := []struct {
+		flags   map[string]string
+		want    int
+		wantErr bool
+	}{
+		{map[string]string{}, 7, false},
+		{map[string]string{"count": "3"}, 3, false},
+		{map[string]string{"count": "0"}, 0, true},
+		{map[string]string{"count": "-2"}, 0, true},
+		{map[string]string{"count": "many"}, 0, true},
+		{map[string]string{"count": "true"}, 0, true},
+	}
+
+	for _, test := range tests {
+		got, err := intFlag(test.flags, "count", 7)
+		if got != test.want || (err != nil) != test.wantErr {
+			t.Errorf("intFlag(%v) = %d, %v, want %d with error %v", test.flags, got, err, test.want, test.wantErr)
+		}
+	}
+}
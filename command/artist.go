@@ -0,0 +1,218 @@
+package command
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/rocketbang/spotify-controller/spotify"
+)
+
+var artistHelp = "Use artist to see the current artist's top tracks, albums and related artists\n" +
+	"Use artist follow or artist unfollow to follow or unfollow the current artist\n" +
+	"Use artist following to list the artists you follow\n" +
+	"Use artist play to play the current artist's top tracks\n" +
+	"Use artist discography to make a playlist of every album by the current artist, add --singles to include singles and EPs"
+
+func artistCommand(args string) {
+	flags, rest := splitFlags(args)
+	action := strings.ToLower(strings.TrimSpace(rest))
+
+	if action == "following" {
+		listFollowedArtists()
+		return
+	}
+
+	song := spotify.GetCurrentSong()
+	if song == nil {
+		fmt.Println("Could not get current song")
+		return
+	}
+	if song.PrimaryArtistID == "" {
+		fmt.Printf("%s has no artist on spotify\n", song.Name)
+		return
+	}
+
+	switch action {
+	case "":
+		showArtist(song.PrimaryArtistID)
+	case "follow":
+		err := spotify.FollowArtist(song.PrimaryArtistID)
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+		fmt.Printf("Following %s\n", song.PrimaryArtist)
+	case "unfollow":
+		err := spotify.UnfollowArtist(song.PrimaryArtistID)
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+		fmt.Printf("Unfollowed %s\n", song.PrimaryArtist)
+	case "play":
+		tracks := spotify.GetArtistTopTracks(song.PrimaryArtistID)
+		if len(tracks) == 0 {
+			fmt.Printf("Could not get top tracks for %s\n", song.PrimaryArtist)
+			return
+		}
+		uris := make([]string, len(tracks))
+		for i, track := range tracks {
+			uris[i] = track.URI
+		}
+		fmt.Printf("Playing the top tracks of %s\n", song.PrimaryArtist)
+		playAllTracks(uris, "")
+	case "discography":
+		discographyPlaylist(song.PrimaryArtistID, song.PrimaryArtist, flags["singles"] != "")
+	default:
+		fmt.Println(artistHelp)
+	}
+}
+
+func listFollowedArtists() {
+	artists := spotify.GetFollowedArtists()
+	if artists == nil {
+		fmt.Println("Could not get followed artists")
+		return
+	}
+	if len(artists) == 0 {
+		fmt.Println("You don't follow any artists")
+		return
+	}
+
+	sort.SliceStable(artists, func(i, j int) bool {
+		return strings.ToLower(artists[i].Name) < strings.ToLower(artists[j].Name)
+	})
+	for i, artist := range artists {
+		fmt.Printf("%d. %s\n", i+1, artist.Name)
+	}
+}
+
+func showArtist(artistID string) {
+	artists := spotify.GetArtists([]string{artistID})
+	artist := artists[artistID]
+	if artist == nil {
+		fmt.Println("Could not get artist")
+		return
+	}
+
+	following := ""
+	if isFollowing, err := spotify.IsFollowingArtist(artistID); err == nil && isFollowing {
+		following = ", followed by you"
+	}
+	fmt.Printf("%s - %d followers%s\n", artist.Name, artist.Followers.Total, following)
+	if len(artist.Genres) > 0 {
+		fmt.Printf("Genres: %s\n", strings.Join(artist.Genres, ", "))
+	}
+
+	fmt.Println("\nTop tracks:")
+	for i, track := range spotify.GetArtistTopTracks(artistID) {
+		fmt.Printf("%d. %s - %s\n", i+1, track.Name, track.Album.Name)
+	}
+
+	fmt.Println("\nAlbums:")
+	for _, album := range spotify.GetArtistAlbums(artistID, []string{"album"}) {
+		fmt.Printf("%s %s\n", releaseYear(album.ReleaseDate), album.Name)
+	}
+
+	related := spotify.GetRelatedArtists(artistID)
+	if len(related) > 0 {
+		names := make([]string, len(related))
+		for i, relatedArtist := range related {
+			names[i] = relatedArtist.Name
+		}
+		fmt.Printf("\nRelated artists: %s\n", strings.Join(names, ", "))
+	}
+}
+
+func releaseYear(releaseDate string) string {
+	if len(releaseDate) < 4 {
+		return "????"
+	}
+	return releaseDate[:4]
+}
+
+// discographyPlaylist makes a playlist with every track of every album by the artist, oldest first
+func discographyPlaylist(artistID string, artistName string, singles bool) {
+	groups := []string{"album"}
+	if singles {
+		groups = append(groups, "single")
+	}
+
+	albums := spotify.GetArtistAlbums(artistID, groups)
+	if albums == nil {
+		fmt.Println("Could not get albums")
+		return
+	}
+	if len(albums) == 0 {
+		fmt.Printf("Could not find any albums by %s\n", artistName)
+		return
+	}
+
+	sort.SliceStable(albums, func(i, j int) bool {
+		return albums[i].ReleaseDate < albums[j].ReleaseDate
+	})
+
+	// The same recording is often on an album and a single, so only keep the first copy of each
+	uris := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, album := range albums {
+		tracks := albumTracksWithISRC(album.ID)
+		if tracks == nil {
+			fmt.Printf("Could not get tracks for %s\n", album.Name)
+			return
+		}
+		for _, track := range tracks {
+			key := recordingKey(track)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			uris = append(uris, track.URI)
+		}
+	}
+
+	defaultName := artistName + " – Complete Discography"
+	fmt.Printf("Found %d tracks on %d releases\n", len(uris), len(albums))
+	fmt.Printf("Enter new playlist name (%s)\n", defaultName)
+	playlistName := getString(defaultName)
+
+	_, err := createPlaylistWithTracks(playlistName, uris)
+	if err != nil {
+		fmt.Println(err.Error())
+	}
+}
+
+// albumTracksWithISRC gets the tracks on the album with their external IDs, which album track listings leave out
+func albumTracksWithISRC(albumID string) []*spotify.Track {
+	albumTracks := spotify.GetAlbumTracks(albumID)
+	if albumTracks == nil {
+		return nil
+	}
+
+	tracks := make([]*spotify.Track, 0, len(albumTracks))
+	for start := 0; start < len(albumTracks); start += 50 {
+		end := start + 50
+		if end > len(albumTracks) {
+			end = len(albumTracks)
+		}
+		ids := make([]string, 0, end-start)
+		for _, track := range albumTracks[start:end] {
+			ids = append(ids, track.ID)
+		}
+		batch := spotify.GetTracks(ids)
+		if batch == nil {
+			return nil
+		}
+		tracks = append(tracks, batch...)
+	}
+	return tracks
+}
+
+// recordingKey identifies the recording a track is of, by ISRC or by title and length if it has none
+func recordingKey(track *spotify.Track) string {
+	if track.ExternalIds.Isrc != "" {
+		return track.ExternalIds.Isrc
+	}
+	return fmt.Sprintf("%s %d", strings.ToLower(track.Name), track.DurationMs)
+}
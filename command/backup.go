@@ -0,0 +1,268 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rocketbang/spotify-controller/backup"
+	"github.com/rocketbang/spotify-controller/spotify"
+)
+
+// backupPlaylist will store the current track list of the playlist if this snapshot isn't already stored
+//
+// Returns false if the backup failed
+func backupPlaylist(playlistID string, reason string) bool {
+	playlist := spotify.GetPlaylist(playlistID)
+	if playlist == nil {
+		fmt.Println("Could not get playlist to back up")
+		return false
+	}
+
+	if backup.Has(playlist.ID, playlist.SnapshotID) {
+		return true
+	}
+
+	items, err := allPlaylistTracks(playlist)
+	if err != nil {
+		fmt.Printf("Could not get tracks to back up %s\n", playlist.Name)
+		return false
+	}
+
+	// Unavailable tracks have no URI, they're kept as empty strings so the positions match the playlist
+	uris := make([]string, 0, len(items))
+	for _, item := range items {
+		uris = append(uris, item.Track.URI)
+	}
+
+	err = backup.Save(playlist.ID, &backup.Snapshot{
+		SnapshotID:   playlist.SnapshotID,
+		PlaylistName: playlist.Name,
+		CreatedAt:    time.Now(),
+		Reason:       reason,
+		URIs:         uris,
+	})
+	if err != nil {
+		fmt.Printf("Could not back up %s: %s\n", playlist.Name, err.Error())
+		return false
+	}
+	return true
+}
+
+// backupBeforeChange backs up the playlist and asks whether to continue if the backup failed
+func backupBeforeChange(playlistID string, reason string) bool {
+	if backupPlaylist(playlistID, reason) {
+		return true
+	}
+	fmt.Println("Continue without a backup? (y/n)")
+	return getConfirm()
+}
+
+func backupCommand(args string) {
+	if args == "all" {
+		playlists := spotify.GetPlaylists()
+		if playlists == nil {
+			fmt.Println("Could not get playlists")
+			return
+		}
+
+		backedUp := 0
+		for _, playlist := range playlists {
+			if backupPlaylist(playlist.ID, "backup all") {
+				backedUp++
+			}
+		}
+		fmt.Printf("Backed up %d of %d playlists\n", backedUp, len(playlists))
+		return
+	}
+
+	playlist := findPlaylist(args)
+	if playlist == nil {
+		return
+	}
+
+	if backupPlaylist(playlist.ID, "backup") {
+		fmt.Printf("Backed up %s\n", playlist.Name)
+	}
+}
+
+func printHistory(args string) {
+	playlist := findPlaylist(args)
+	if playlist == nil {
+		return
+	}
+
+	snapshots, err := backup.History(playlist.ID)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	if len(snapshots) == 0 {
+		fmt.Printf("No backups found for %s\n", playlist.Name)
+		return
+	}
+
+	fmt.Printf("Backups of %s:\n", playlist.Name)
+	for i, snapshot := range snapshots {
+		fmt.Printf("%d. %s - %d tracks (%s) %s\n", i+1, snapshot.CreatedAt.Format("2006-01-02 15:04"), len(snapshot.URIs), snapshot.Reason, shortSnapshotID(snapshot.SnapshotID))
+	}
+}
+
+func restorePlaylist(args string) {
+	fields := strings.Fields(args)
+	if len(fields) < 2 {
+		fmt.Println("Use restore <playlist> <backup number or snapshot id>")
+		return
+	}
+
+	playlist := findPlaylist(strings.Join(fields[:len(fields)-1], " "))
+	if playlist == nil {
+		return
+	}
+
+	snapshot, err := backup.Find(playlist.ID, fields[len(fields)-1])
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	uris := make([]string, 0, len(snapshot.URIs))
+	local := 0
+	unavailable := 0
+	for _, uri := range snapshot.URIs {
+		// Local files can't be added through the web api
+		if strings.HasPrefix(uri, "spotify:local:") {
+			local++
+			continue
+		}
+		if uri == "" {
+			unavailable++
+			continue
+		}
+		uris = append(uris, uri)
+	}
+	if local > 0 {
+		fmt.Printf("%d local files can't be restored and will be skipped\n", local)
+	}
+	if unavailable > 0 {
+		fmt.Printf("%d tracks were unavailable when backed up and will be skipped\n", unavailable)
+	}
+
+	fmt.Printf("Restore %s to %d tracks from %s? (y/n)\n", playlist.Name, len(uris), snapshot.CreatedAt.Format("2006-01-02 15:04"))
+	if !getConfirm() {
+		return
+	}
+
+	if !backupBeforeChange(playlist.ID, "restore") {
+		return
+	}
+
+	err = spotify.ReplacePlaylistTracks(playlist.ID, uris)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+	fmt.Printf("Restored %s\n", playlist.Name)
+}
+
+// allPlaylistTracks gets every track in the playlist, returning an error rather than a partial list
+func allPlaylistTracks(playlist *spotify.Playlist) ([]*spotify.PlaylistTrackResItem, error) {
+	items := spotify.GetTracksInPlaylist(playlist.ID)
+	if items == nil {
+		return nil, errors.New("Could not get tracks for " + playlist.Name)
+	}
+	for _, item := range items {
+		if item == nil {
+			return nil, errors.New("Could not get every track in " + playlist.Name + ", it changed while loading")
+		}
+	}
+	return items, nil
+}
+
+// findPlaylist will find the users playlist with the given name, or ask the user to choose one if no name is given
+func findPlaylist(name string) *spotify.Playlist {
+	return matchPlaylist(name, false)
+}
+
+// findPlaylistFuzzy is findPlaylist but also allows for missing letters and spaces, e.g. "rdtrip" for "Road Trip"
+//
+// A typo can match an unrelated playlist, so only use it where acting on the wrong playlist is easily put right
+func findPlaylistFuzzy(name string) *spotify.Playlist {
+	return matchPlaylist(name, true)
+}
+
+func matchPlaylist(name string, fuzzy bool) *spotify.Playlist {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return choosePlaylist()
+	}
+
+	playlists := spotify.GetPlaylists()
+	if playlists == nil {
+		fmt.Println("Could not get playlists")
+		return nil
+	}
+
+	lowerName := strings.ToLower(name)
+	matches := make([]*spotify.Playlist, 0)
+	for _, playlist := range playlists {
+		if strings.ToLower(playlist.Name) == lowerName {
+			return playlist
+		}
+		if strings.Contains(strings.ToLower(playlist.Name), lowerName) {
+			matches = append(matches, playlist)
+		}
+	}
+
+	if len(matches) == 0 && fuzzy {
+		for _, playlist := range playlists {
+			if isSubsequence(lowerName, strings.ToLower(playlist.Name)) {
+				matches = append(matches, playlist)
+			}
+		}
+	}
+
+	if len(matches) == 1 {
+		return matches[0]
+	}
+	if len(matches) == 0 {
+		fmt.Printf("Could not find playlist %s\n", name)
+		return nil
+	}
+
+	fmt.Printf("More than one playlist matches %s\n", name)
+	for i, playlist := range matches {
+		fmt.Printf("%d. %s\n", (i + 1), playlist.Name)
+	}
+	playlistNum, err := getInt(1, len(matches))
+	if err != nil {
+		return nil
+	}
+	return matches[playlistNum-1]
+}
+
+// isSubsequence returns true if every letter of search appears in text in the same order, spaces in search are ignored
+func isSubsequence(search string, text string) bool {
+	search = strings.Replace(search, " ", "", -1)
+	runes := []rune(text)
+	next := 0
+	for _, r := range search {
+		for next < len(runes) && runes[next] != r {
+			next++
+		}
+		if next == len(runes) {
+			return false
+		}
+		next++
+	}
+	return true
+}
+
+func shortSnapshotID(snapshotID string) string {
+	if len(snapshotID) > 12 {
+		return snapshotID[:12]
+	}
+	return snapshotID
+}
@@ -11,6 +11,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/rocketbang/spotify-controller/config"
+	"github.com/rocketbang/spotify-controller/history"
 	"github.com/rocketbang/spotify-controller/spotify"
 )
 
@@ -39,9 +41,24 @@ func volume(args string) {
 	spotify.SetVolume(volInt)
 }
 
-func addToPlaylist() {
-	playlist := choosePlaylist()
-	if playlist == nil {
+func addToPlaylist(args string) {
+	flags, rest := splitFlags(args)
+
+	var playlists []*spotify.Playlist
+	if flags["favourites"] != "" {
+		playlists = favouritePlaylists()
+	} else if strings.TrimSpace(rest) != "" {
+		for _, name := range splitQuotedList(rest) {
+			playlist := findPlaylistFuzzy(name)
+			if playlist == nil {
+				return
+			}
+			playlists = append(playlists, playlist)
+		}
+	} else {
+		playlists = choosePlaylists()
+	}
+	if len(playlists) == 0 {
 		return
 	}
 
@@ -51,11 +68,60 @@ func addToPlaylist() {
 		return
 	}
 
+	for _, playlist := range playlists {
+		addSong(song, playlist)
+	}
+}
+
+// addSong adds the song to the playlist, asking first if it's already there
+func addSong(song *spotify.Song, playlist *spotify.Playlist) {
+	locations, err := spotify.FindTrackInPlaylist(playlist.ID, song.URI)
+	if err != nil {
+		fmt.Printf("Could not check if %s is already in %s\n", song.Name, playlist.Name)
+	} else if len(locations) > 0 {
+		for _, location := range locations {
+			fmt.Printf("%s is already in %s at position %d, added %s\n", song.Name, playlist.Name, location.Position+1, location.AddedAt.Format("2006-01-02"))
+		}
+		fmt.Println("Add it anyway? (y/n)")
+		if !getConfirm() {
+			return
+		}
+	}
+
+	if !backupBeforeChange(playlist.ID, "add") {
+		return
+	}
+
 	fmt.Printf("Adding %s to %s\n", song.Name, playlist.Name)
 
 	spotify.AddToPlaylist(playlist.ID, song.URI)
 }
 
+// favouritePlaylists gets the playlists listed in favouritePlaylists in the config
+func favouritePlaylists() []*spotify.Playlist {
+	if len(config.Value.FavouritePlaylists) == 0 {
+		fmt.Println("No favourite playlists are set up, add them to favouritePlaylists in config.json")
+		return nil
+	}
+
+	all := spotify.GetPlaylists()
+	if all == nil {
+		fmt.Println("Could not get playlists")
+		return nil
+	}
+
+	playlists := make([]*spotify.Playlist, 0, len(config.Value.FavouritePlaylists))
+	for _, name := range config.Value.FavouritePlaylists {
+		playlist := lookupPlaylist(all, name)
+		if playlist == nil {
+			fmt.Printf("Could not find favourite playlist %s\n", name)
+			continue
+		}
+		playlists = append(playlists, playlist)
+	}
+	return playlists
+}
+
 func removeFromCurrentPlaylist() {
 	playlist := spotify.GetCurrentPlaylist()
 	if playlist == nil {
@@ -69,11 +135,74 @@ func removeFromCurrentPlaylist() {
 		return
 	}
 
+	if !backupBeforeChange(playlist.ID, "remove") {
+		return
+	}
+
 	fmt.Printf("Removing %s from current playlist\n", song.Name)
 
 	spotify.RemoveFromPlaylist(playlist.ID, song.URI, nil)
 }
 
+func moveToPlaylist(args string) {
+	flags, rest := splitFlags(args)
+
+	current := spotify.GetCurrentPlaylist()
+	if current == nil {
+		return
+	}
+
+	song := spotify.GetCurrentSong()
+	if song == nil {
+		fmt.Println("Could not get current song")
+		return
+	}
+
+	playlist := findPlaylist(rest)
+	if playlist == nil {
+		return
+	}
+	if playlist.ID == current.ID {
+		fmt.Println("The song is already in that playlist")
+		return
+	}
+
+	locations, err := spotify.FindTrackInPlaylist(playlist.ID, song.URI)
+	if err == nil && len(locations) > 0 {
+		fmt.Printf("%s is already in %s, move it anyway? (y/n)\n", song.Name, playlist.Name)
+		if !getConfirm() {
+			return
+		}
+	}
+
+	if !backupBeforeChange(current.ID, "move") || !backupBeforeChange(playlist.ID, "move") {
+		return
+	}
+
+	fmt.Printf("Moving %s to %s\n", song.Name, playlist.Name)
+	err = spotify.MoveToPlaylist(current.ID, playlist.ID, song.URI)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	if flags["next"] == "true" {
+		skipToNext()
+	}
+}
+
+// skipToNext skips to the next track, letting the history recorder know the current track was skipped
+func skipToNext() {
+	history.RecordSkip()
+	spotify.Next()
+}
+
+// skipToPrevious goes back a track, letting the history recorder know the current track wasn't skipped
+func skipToPrevious() {
+	history.RecordPrevious()
+	spotify.Prev()
+}
+
 func removeDuplicatesInPlaylist() {
 	playlist := choosePlaylist()
 	if playlist == nil {
@@ -85,6 +214,9 @@ func removeDuplicatesInPlaylist() {
 	trackMap := make(map[string]*spotify.PlaylistTrackResItem)
 
 	detectedDuplicate := false
+	backedUp := false
+	// Each removal moves the later tracks up one position
+	removed := 0
 	for i := range items {
 		item := items[i]
 		track := item.Track
@@ -96,8 +228,15 @@ func removeDuplicatesInPlaylist() {
 			fmt.Printf("Previous item %s, %s\n", prevItem.Track.Name, prevItem.AddedAt)
 			fmt.Printf("Remove duplicate? (y/n)\n")
 			remove := getConfirm()
+			if remove && !backedUp {
+				if !backupBeforeChange(playlist.ID, "duplicate") {
+					return
+				}
+				backedUp = true
+			}
 			if remove {
-				spotify.RemoveFromPlaylist(playlist.ID, track.URI, []int{i})
+				spotify.RemoveFromPlaylist(playlist.ID, track.URI, []int{i - removed})
+				removed++
 			}
 		} else {
 			trackMap[track.ID] = item
@@ -110,58 +249,59 @@ func removeDuplicatesInPlaylist() {
 
 }
 
-func shuffleInNewPlaylist() {
-	fmt.Println("Choose playlist to shuffle")
-	clonedPlaylist := choosePlaylist()
-	if clonedPlaylist == nil {
+func shuffleInNewPlaylist(playlistName string, flags map[string]string) {
+	strategy, random, err := getShuffleStrategy(flags)
+	if err != nil {
+		fmt.Println(err.Error())
 		return
 	}
 
-	items := spotify.GetTracksInPlaylist(clonedPlaylist.ID)
-	itemURIs := make([]string, len(items))
-	for i, item := range items {
-		itemURIs[i] = item.Track.URI
+	if playlistName == "" {
+		fmt.Println("Choose playlist to shuffle")
+	}
+	clonedPlaylist := findPlaylist(playlistName)
+	if clonedPlaylist == nil {
+		return
 	}
 
-	rand.Seed(time.Now().UnixNano())
-	rand.Shuffle(len(itemURIs), func(i, j int) { itemURIs[i], itemURIs[j] = itemURIs[j], itemURIs[i] })
+	items := spotify.GetTracksInPlaylist(clonedPlaylist.ID)
+	itemURIs := shuffledURIs(items, strategy, random)
 
-	spotify.PlayTracks(itemURIs, clonedPlaylist.URI)
+	playAllTracks(itemURIs, clonedPlaylist.URI)
 }
 
-func clonePlaylist() {
-	userID, err := spotify.GetUserID()
+func clonePlaylist(args string) {
+	flags, _ := splitFlags(args)
+	strategy, random, err := getShuffleStrategy(flags)
 	if err != nil {
-		fmt.Println("Could not get user id")
+		fmt.Println(err.Error())
 		return
 	}
 
 	fmt.Println("Choose playlist to clone")
 	clonedPlaylist := choosePlaylist()
+	if clonedPlaylist == nil {
+		return
+	}
 
 	fmt.Println("Enter new playlist name (New Playlist)")
 	playlistName := getString("New Playlist")
 
-	playlistID, err := spotify.CreateNewPlaylist(userID, playlistName, false)
+	items := spotify.GetTracksInPlaylist(clonedPlaylist.ID)
+	itemURIs := shuffledURIs(items, strategy, random)
+
+	playlistID, err := createPlaylistWithTracks(playlistName, itemURIs)
 	if err != nil {
-		fmt.Println("Could not create new playlist")
+		fmt.Println(err.Error())
 		return
 	}
 
-	// Need to wait here because sometimes the playlist isn't created soon enough
-	time.Sleep(time.Duration(4) * time.Second)
-
-	items := spotify.GetTracksInPlaylist(clonedPlaylist.ID)
-	itemURIs := make([]string, len(items))
-
-	for i, item := range items {
-		itemURIs[i] = item.Track.URI
+	if flags["cover"] != "" {
+		err = setMosaicCover(playlistID, playlistName, nonNilItems(items))
+		if err != nil {
+			fmt.Println(err.Error())
+		}
 	}
-
-	rand.Seed(time.Now().UnixNano())
-	rand.Shuffle(len(itemURIs), func(i, j int) { itemURIs[i], itemURIs[j] = itemURIs[j], itemURIs[i] })
-
-	spotify.AddManyToPlaylist(playlistID, itemURIs)
 }
 
 func choosePlaylist() *spotify.Playlist {
@@ -191,8 +331,37 @@ func choosePlaylist() *spotify.Playlist {
 	return playlist
 }
 
+// choosePlaylists lets the user pick one or more playlists, e.g. 1,4,7
+func choosePlaylists() []*spotify.Playlist {
+	playlists := spotify.GetPlaylists()
+
+	if playlists == nil {
+		fmt.Println("Could not get playlists")
+		return nil
+	}
+
+	fmt.Printf("Choose Playlists (e.g. 1,4,7):\n")
+	for i, playlist := range playlists {
+		fmt.Printf("%d. %s\n", (i + 1), playlist.Name)
+	}
+
+	playlistNums, err := getInts(1, len(playlists))
+	if err != nil {
+		return nil
+	}
+
+	chosen := make([]*spotify.Playlist, len(playlistNums))
+	for i, playlistNum := range playlistNums {
+		chosen[i] = playlists[playlistNum-1]
+	}
+	return chosen
+}
+
 func playingStatus() {
 	song := spotify.GetCurrentSong()
+	if song == nil {
+		return
+	}
 	fmt.Printf("Current track is: %s by %s. - %s\n", song.Name, song.PrimaryArtist, song.Album)
 }
 
@@ -215,6 +384,34 @@ func getInt(min int, max int) (int, error) {
 	return number, nil
 }
 
+// getInts reads a comma separated list of numbers, skipping any repeats
+func getInts(min int, max int) ([]int, error) {
+	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Scan()
+
+	numbers := make([]int, 0)
+	seen := make(map[int]bool)
+	for _, intStr := range strings.Split(scanner.Text(), ",") {
+		number, err := strconv.Atoi(strings.TrimSpace(intStr))
+		if err != nil {
+			fmt.Println("Could not read number")
+			return nil, errors.New("")
+		}
+
+		if number < min || number > max {
+			fmt.Println("Number is out of bounds")
+			return nil, errors.New("")
+		}
+
+		if !seen[number] {
+			seen[number] = true
+			numbers = append(numbers, number)
+		}
+	}
+
+	return numbers, nil
+}
+
 func getString(auto string) string {
 	scanner := bufio.NewScanner(os.Stdin)
 	scanner.Scan()
@@ -298,6 +495,7 @@ func printHelp(commands []*commandStruct, args string) {
 type returnedSong struct {
 	Name       string
 	ArtistName string
+	URI        string
 }
 
 func getNextNSongs(n int, playlistURI string, abort <-chan struct{}) <-chan *returnedSong {
@@ -311,9 +509,12 @@ func getNextNSongs(n int, playlistURI string, abort <-chan struct{}) <-chan *ret
 		defer close(ch)
 		for i := 0; i < n; i++ {
 			song := spotify.GetCurrentSong()
+			if song == nil {
+				return
+			}
 
 			select {
-			case ch <- &returnedSong{Name: song.Name, ArtistName: song.PrimaryArtist}:
+			case ch <- &returnedSong{Name: song.Name, ArtistName: song.PrimaryArtist, URI: song.URI}:
 				spotify.Next()
 				time.Sleep(250 * time.Millisecond)
 			case <-abort: // receive on closed channel can proceed immediately
@@ -382,52 +583,14 @@ func printRandomNSongs(n int) {
 	}
 }
 
-func test() {
-	playlist := choosePlaylist()
-	if playlist == nil {
-		return
-	}
-
-	n := 40
-	totalRounds := 20
-
-	for round := 0; round < totalRounds; round++ {
-		randomSongPos := -1
-		shuffledSongPos := -1
-
-		abort := make(chan struct{})
-		ch := getNextNSongs(n, playlist.URI, abort)
-
-		songIndex := 0
-		for song := range ch {
-			if song.Name == "Drought" {
-				shuffledSongPos = songIndex
-				close(abort)
-			}
-			songIndex++
-		}
-
-		randomSongs := getNextNSongsRandom(n, playlist.ID)
-		// shuffledSongs := getNextNSongsSync(n, playlist.URI)
-
-		for i := 0; i < n; i++ {
-			if randomSongs[i].Name == "Drought" {
-				randomSongPos = i
-			}
-			// if shuffledSongs[i].Name == "Drought" {
-			// 	shuffledSongPos = i
-			// }
-		}
-
-		fmt.Printf("Random: %d, Shuffled: %d\n", randomSongPos, shuffledSongPos)
-	}
-
-}
-
 // Listen will listen for the given commands until the user exits
 func Listen() {
 	rand.Seed(time.Now().UnixNano())
 
+	if config.Value.RecordHistory {
+		history.StartRecording()
+	}
+
 	commands := make([]*commandStruct, 0)
 
 	commands = append(commands, &commandStruct{
@@ -458,33 +621,177 @@ func Listen() {
 	})
 	commands = append(commands, &commandStruct{
 		Name:    "Add",
-		Help:    "Adds the currently playing song to a playlist of your choice",
-		Run:     func(a string) { addToPlaylist() },
+		Help:    "Adds the currently playing song to a playlist of your choice\nUse add [playlist], add <playlist>, <playlist> or pick several from the list, e.g. 1,4,7\nPut quotes around a playlist name with a comma in it, e.g. add \"Rock, Paper\", Chill\nUse add --favourites to add it to every playlist in favouritePlaylists in config.json",
+		Run:     addToPlaylist,
 		CmdText: []string{"add"},
 	})
+	commands = append(commands, &commandStruct{
+		Name:    "Move",
+		Help:    "Moves the currently playing song from the current playlist to another\nUse move [playlist], add --next to skip to the next track afterwards\nThe move is recorded as an add and a remove, so use undo twice to reverse it",
+		Run:     moveToPlaylist,
+		CmdText: []string{"move"},
+	})
+	commands = append(commands, &commandStruct{
+		Name:    "Playlist",
+		Help:    "Creates, renames, describes and deletes playlists or changes who can see them\n" + playlistHelp,
+		Run:     playlistCommand,
+		CmdText: []string{"playlist"},
+	})
+	commands = append(commands, &commandStruct{
+		Name:    "Cover",
+		Help:    "Changes the cover image of a playlist\nUse cover [playlist] <image file>, quote paths with spaces\nPNG and JPEG images are cropped to a square and made small enough for spotify",
+		Run:     coverCommand,
+		CmdText: []string{"cover"},
+	})
+	commands = append(commands, &commandStruct{
+		Name:    "Artist",
+		Help:    "Shows, follows and plays the current artist or makes a playlist of their discography\n" + artistHelp,
+		Run:     artistCommand,
+		CmdText: []string{"artist"},
+	})
+	commands = append(commands, &commandStruct{
+		Name:    "Top",
+		Help:    "Lists your most played tracks and artists or saves your top tracks to a playlist\n" + topHelp,
+		Run:     topCommand,
+		CmdText: []string{"top"},
+	})
+	commands = append(commands, &commandStruct{
+		Name:    "Recent",
+		Help:    "Lists your recently played tracks or saves them to a playlist\n" + recentHelp,
+		Run:     recentCommand,
+		CmdText: []string{"recent"},
+	})
+	commands = append(commands, &commandStruct{
+		Name:    "Record",
+		Help:    "Records every track you play to a local listening history\nUse record on, record off or record status\nSet recordHistory to true in config.json to start recording whenever the app starts",
+		Run:     recordCommand,
+		CmdText: []string{"record"},
+	})
+	commands = append(commands, &commandStruct{
+		Name:    "Stats",
+		Help:    "Shows your top tracks, listening time, skips and when you listen from the local listening history\n" + statsHelp,
+		Run:     statsCommand,
+		CmdText: []string{"stats"},
+	})
+	commands = append(commands, &commandStruct{
+		Name:    "Prune",
+		Help:    "Removes the tracks you keep skipping from a playlist\n" + pruneHelp,
+		Run:     prunePlaylist,
+		CmdText: []string{"prune"},
+	})
+	commands = append(commands, &commandStruct{
+		Name:    "Radio",
+		Help:    "Queues tracks recommended from the current song\nUse radio [number of tracks], 20 are queued if no number is given\n" + targetsHelp,
+		Run:     radio,
+		CmdText: []string{"radio"},
+	})
+	commands = append(commands, &commandStruct{
+		Name:    "Extend",
+		Help:    "Adds recommended tracks that aren't already in a playlist\nUse extend [playlist] <number of tracks>, recommendations are based on a random sample of the playlist\n" + targetsHelp,
+		Run:     extendPlaylist,
+		CmdText: []string{"extend"},
+	})
 	commands = append(commands, &commandStruct{
 		Name:    "Shuffle",
-		Help:    "Randomly shuffles the given playlist",
-		Run:     func(a string) { shuffleInNewPlaylist() },
+		Help:    "Randomly shuffles the given playlist\nUse shuffle [playlist] to play it shuffled or shuffle --in-place [playlist] to permanently give the playlist a new order\nAdd --fast to replace the track list in one go, this resets the date each track was added\n" + shuffleFlagsHelp,
+		Run:     shuffleCommand,
 		CmdText: []string{"shuffle"},
 	})
 	commands = append(commands, &commandStruct{
 		Name:    "Clone",
-		Help:    "Clones the given playlist to a new playlist with a randomly shuffled order",
-		Run:     func(a string) { clonePlaylist() },
+		Help:    "Clones the given playlist to a new playlist with a randomly shuffled order\n" + shuffleFlagsHelp + "\nUse --cover to give the new playlist a cover made from its album art",
+		Run:     clonePlaylist,
 		CmdText: []string{"clone"},
 	})
+	commands = append(commands, &commandStruct{
+		Name:    "Sort",
+		Help:    "Sorts a playlist\nUse sort [playlist] by <field> [asc|desc], <field> [asc|desc]...\nFields are " + strings.Join(sortFieldNames(), ", ") + "\nThe playlist is sorted in place, use --new[=name] to put the sorted tracks in a new playlist instead",
+		Run:     sortPlaylist,
+		CmdText: []string{"sort"},
+	})
+	commands = append(commands, &commandStruct{
+		Name:    "Split",
+		Help:    "Splits a playlist into several new playlists\n" + splitHelp,
+		Run:     splitPlaylist,
+		CmdText: []string{"split"},
+	})
+	commands = append(commands, &commandStruct{
+		Name:    "Merge",
+		Help:    "Creates a playlist with the tracks of two playlists\n" + fmt.Sprintf(setOperationHelp, "merge"),
+		Run:     mergePlaylists,
+		CmdText: []string{"merge"},
+	})
+	commands = append(commands, &commandStruct{
+		Name:    "Intersect",
+		Help:    "Creates a playlist with the tracks that are in both of two playlists\n" + fmt.Sprintf(setOperationHelp, "intersect"),
+		Run:     intersectPlaylists,
+		CmdText: []string{"intersect"},
+	})
+	commands = append(commands, &commandStruct{
+		Name:    "Subtract",
+		Help:    "Creates a playlist with the tracks of the first playlist that aren't in the second\n" + fmt.Sprintf(setOperationHelp, "subtract"),
+		Run:     subtractPlaylists,
+		CmdText: []string{"subtract"},
+	})
+	commands = append(commands, &commandStruct{
+		Name:    "Diff",
+		Help:    "Shows the tracks added, removed and moved between two playlists\nUse diff <before> <after>, quote names with spaces\nEach can be a playlist, a csv, m3u or json file, or a backup written as <playlist>@<backup number>\nUse diff <playlist> to compare a playlist to its latest backup",
+		Run:     diffPlaylists,
+		CmdText: []string{"diff"},
+	})
+	commands = append(commands, &commandStruct{
+		Name:    "Smart",
+		Help:    "Refreshes the smart playlists set up in config.json\nUse smart list to see them or smart sync [name] to update them\nOnly the tracks that changed are added or removed",
+		Run:     smartCommand,
+		CmdText: []string{"smart"},
+	})
+	commands = append(commands, &commandStruct{
+		Name:    "Import",
+		Help:    "Imports a playlist from a csv, m3u or json file\nUse import <file> [playlist name], put quotes around a file path with spaces in it\nTracks are matched by spotify URI, then ISRC, then title and artist",
+		Run:     importPlaylist,
+		CmdText: []string{"import"},
+	})
+	commands = append(commands, &commandStruct{
+		Name:    "Backup",
+		Help:    "Backs up the track list of a playlist\nUse backup [playlist] or backup all\nPlaylists are also backed up before any change is made to them",
+		Run:     backupCommand,
+		CmdText: []string{"backup"},
+	})
+	commands = append(commands, &commandStruct{
+		Name:    "History",
+		Help:    "Lists the backups of a playlist\nUse history [playlist]",
+		Run:     printHistory,
+		CmdText: []string{"history"},
+	})
+	commands = append(commands, &commandStruct{
+		Name:    "Restore",
+		Help:    "Restores a playlist to a backup\nUse restore <playlist> <backup number or snapshot id>\nBackup numbers are listed by the history command",
+		Run:     restorePlaylist,
+		CmdText: []string{"restore"},
+	})
+	commands = append(commands, &commandStruct{
+		Name:    "Undo",
+		Help:    "Undoes the last change made to a playlist this session\nSorting, shuffling in place or restoring a playlist clears its earlier changes as their positions no longer line up",
+		Run:     func(a string) { undo() },
+		CmdText: []string{"undo"},
+	})
+	commands = append(commands, &commandStruct{
+		Name:    "Redo",
+		Help:    "Makes the last undone playlist change again",
+		Run:     func(a string) { redo() },
+		CmdText: []string{"redo"},
+	})
 	commands = append(commands, &commandStruct{
 		Name:    "Next",
 		Help:    "Skips to the next track",
-		Run:     func(a string) { spotify.Next() },
+		Run:     func(a string) { skipToNext() },
 		RunText: "Next track",
 		CmdText: []string{"next"},
 	})
 	commands = append(commands, &commandStruct{
 		Name:    "Previous",
 		Help:    "Skips back to the previous track",
-		Run:     func(a string) { spotify.Prev() },
+		Run:     func(a string) { skipToPrevious() },
 		RunText: "Previous track",
 		CmdText: []string{"prev", "previous"},
 	})
@@ -520,10 +827,10 @@ func Listen() {
 		CmdText: []string{"rand"},
 	})
 	commands = append(commands, &commandStruct{
-		Name:    "Run test",
-		Help:    "Tests spotify shuffle feature",
-		Run:     func(a string) { test() },
-		CmdText: []string{"test"},
+		Name:    "Analyze shuffle",
+		Help:    "Compares spotify's shuffle of a playlist against a uniform shuffle\nUse analyze-shuffle [playlist] [--rounds=20] [--depth=40] [--track=<name or uri>,...] [--csv=<file>]\nThe playlist is played with shuffle on and the order is read from the queue, use --skip to read it by skipping through tracks instead\nUse --mode to compare against a different shuffle mode and --local-only to not use spotify playback",
+		Run:     analyzeShuffle,
+		CmdText: []string{"analyze-shuffle"},
 	})
 
 	scanner := bufio.NewScanner(os.Stdin)
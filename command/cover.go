@@ -0,0 +1,48 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/rocketbang/spotify-controller/cover"
+	"github.com/rocketbang/spotify-controller/spotify"
+)
+
+func coverCommand(args string) {
+	fields := splitQuoted(args)
+	if len(fields) == 0 {
+		fmt.Println("Use cover [playlist] <image file>")
+		return
+	}
+
+	path := fields[len(fields)-1]
+	if _, err := os.Stat(path); err != nil {
+		fmt.Printf("Could not find image %s\n", path)
+		return
+	}
+
+	playlist := findPlaylist(strings.Join(fields[:len(fields)-1], " "))
+	if playlist == nil {
+		return
+	}
+
+	img, err := cover.Load(path)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	jpegImage, err := cover.Fit(img, spotify.MaxCoverSize)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	err = spotify.UploadPlaylistCover(playlist.ID, jpegImage)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+	fmt.Printf("Changed the cover of %s\n", playlist.Name)
+}
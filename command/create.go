@@ -0,0 +1,32 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rocketbang/spotify-controller/spotify"
+)
+
+// createPlaylistWithTracks creates a new private playlist holding the given tracks and returns its ID
+func createPlaylistWithTracks(playlistName string, songURIs []string) (string, error) {
+	userID, err := spotify.GetUserID()
+	if err != nil {
+		return "", errors.New("Could not get user id")
+	}
+
+	playlistID, err := spotify.CreateNewPlaylist(userID, playlistName, false)
+	if err != nil {
+		return "", errors.New("Could not create new playlist")
+	}
+
+	// Need to wait here because sometimes the playlist isn't created soon enough
+	time.Sleep(time.Duration(4) * time.Second)
+
+	err = spotify.AddManyToPlaylist(playlistID, songURIs)
+	if err != nil {
+		return "", err
+	}
+	fmt.Printf("Created %s with %d tracks\n", playlistName, len(songURIs))
+	return playlistID, nil
+}
@@ -0,0 +1,223 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rocketbang/spotify-controller/backup"
+	"github.com/rocketbang/spotify-controller/spotify"
+	"github.com/rocketbang/spotify-controller/tracklist"
+)
+
+// trackList is a named list of tracks to compare
+type trackList struct {
+	Name    string
+	Entries []*tracklist.Entry
+}
+
+func diffPlaylists(args string) {
+	sources := splitQuoted(args)
+	if len(sources) > 2 {
+		fmt.Println("Use diff <playlist, file or playlist@backup> [playlist, file or playlist@backup]")
+		return
+	}
+
+	var before *trackList
+	var after *trackList
+	var err error
+	if len(sources) < 2 {
+		// With one playlist compare its latest backup to how it is now
+		name := ""
+		if len(sources) == 1 {
+			name = sources[0]
+		}
+		playlist := findPlaylist(name)
+		if playlist == nil {
+			return
+		}
+
+		before, err = loadLatestBackup(playlist)
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+		after = loadPlaylistTrackList(playlist)
+	} else {
+		before, err = loadTrackList(sources[0])
+		if err == nil && before != nil {
+			after, err = loadTrackList(sources[1])
+		}
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+	}
+
+	if before == nil || after == nil {
+		return
+	}
+
+	diff := tracklist.Compare(before.Entries, after.Entries)
+	fillTitles(diff)
+	printDiff(before, after, diff)
+}
+
+// loadTrackList loads a track list file, a backup written as playlist@backup or a playlist
+//
+// Returns nil without an error if the playlist could not be found
+func loadTrackList(source string) (*trackList, error) {
+	if _, err := os.Stat(source); err == nil {
+		entries, err := tracklist.ReadFile(source)
+		if err != nil {
+			return nil, err
+		}
+		return &trackList{Name: filepath.Base(source), Entries: entries}, nil
+	}
+
+	if at := strings.LastIndex(source, "@"); at > 0 {
+		playlist := findPlaylist(source[:at])
+		if playlist == nil {
+			return nil, nil
+		}
+
+		snapshot, err := backup.Find(playlist.ID, source[at+1:])
+		if err != nil {
+			return nil, err
+		}
+		return snapshotTrackList(playlist, snapshot), nil
+	}
+
+	playlist := findPlaylist(source)
+	if playlist == nil {
+		return nil, nil
+	}
+	list := loadPlaylistTrackList(playlist)
+	if list == nil {
+		return nil, errors.New("Could not get tracks for " + playlist.Name)
+	}
+	return list, nil
+}
+
+func loadLatestBackup(playlist *spotify.Playlist) (*trackList, error) {
+	snapshots, err := backup.History(playlist.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(snapshots) == 0 {
+		return nil, errors.New("No backups found for " + playlist.Name)
+	}
+	return snapshotTrackList(playlist, snapshots[len(snapshots)-1]), nil
+}
+
+func snapshotTrackList(playlist *spotify.Playlist, snapshot *backup.Snapshot) *trackList {
+	entries := make([]*tracklist.Entry, len(snapshot.URIs))
+	for i, uri := range snapshot.URIs {
+		entries[i] = &tracklist.Entry{Row: i + 1, URI: uri}
+	}
+	name := fmt.Sprintf("%s backup from %s", playlist.Name, snapshot.CreatedAt.Format("2006-01-02 15:04"))
+	return &trackList{Name: name, Entries: entries}
+}
+
+func loadPlaylistTrackList(playlist *spotify.Playlist) *trackList {
+	items := spotify.GetTracksInPlaylist(playlist.ID)
+	if items == nil {
+		return nil
+	}
+
+	entries := make([]*tracklist.Entry, 0, len(items))
+	for _, item := range nonNilItems(items) {
+		entry := &tracklist.Entry{
+			Row:     len(entries) + 1,
+			URI:     item.Track.URI,
+			ISRC:    item.Track.ExternalIds.Isrc,
+			Title:   item.Track.Name,
+			AddedBy: item.AddedBy.ID,
+			AddedAt: item.AddedAt,
+		}
+		if len(item.Track.Artists) > 0 {
+			entry.Artist = item.Track.Artists[0].Name
+		}
+		entries = append(entries, entry)
+	}
+	return &trackList{Name: playlist.Name, Entries: entries}
+}
+
+// fillTitles looks up the names of tracks that only have a URI, such as those from backups
+func fillTitles(diff *tracklist.Diff) {
+	entries := append(append([]*tracklist.Entry{}, diff.Added...), diff.Removed...)
+	for _, move := range diff.Moved {
+		entries = append(entries, move.Entry)
+	}
+
+	byID := make(map[string][]*tracklist.Entry)
+	ids := make([]string, 0)
+	for _, entry := range entries {
+		id := entry.TrackID()
+		if entry.Title != "" || id == "" {
+			continue
+		}
+		if _, ok := byID[id]; !ok {
+			ids = append(ids, id)
+		}
+		byID[id] = append(byID[id], entry)
+	}
+
+	for start := 0; start < len(ids); start += 50 {
+		end := start + 50
+		if end > len(ids) {
+			end = len(ids)
+		}
+		for _, track := range spotify.GetTracks(ids[start:end]) {
+			for _, entry := range byID[track.ID] {
+				entry.Title = track.Name
+				if len(track.Artists) > 0 {
+					entry.Artist = track.Artists[0].Name
+				}
+			}
+		}
+	}
+}
+
+func printDiff(before *trackList, after *trackList, diff *tracklist.Diff) {
+	fmt.Printf("Comparing %s (%d tracks) to %s (%d tracks)\n", before.Name, len(before.Entries), after.Name, len(after.Entries))
+
+	if len(diff.Added) > 0 {
+		fmt.Printf("\nAdded (%d):\n", len(diff.Added))
+		for _, entry := range diff.Added {
+			added := ""
+			if entry.AddedBy != "" {
+				added = " by " + entry.AddedBy
+			}
+			if !entry.AddedAt.IsZero() {
+				added += " on " + entry.AddedAt.Format("2006-01-02")
+			}
+			if added != "" {
+				added = " (added" + added + ")"
+			}
+			fmt.Printf("+ %s%s\n", entry.Label(), added)
+		}
+	}
+
+	if len(diff.Removed) > 0 {
+		fmt.Printf("\nRemoved (%d):\n", len(diff.Removed))
+		for _, entry := range diff.Removed {
+			fmt.Printf("- %s\n", entry.Label())
+		}
+	}
+
+	if len(diff.Moved) > 0 {
+		fmt.Printf("\nMoved (%d):\n", len(diff.Moved))
+		for _, move := range diff.Moved {
+			fmt.Printf("~ %s %d -> %d\n", move.Entry.Label(), move.From+1, move.To+1)
+		}
+	}
+
+	if len(diff.Added) == 0 && len(diff.Removed) == 0 && len(diff.Moved) == 0 {
+		fmt.Println("No differences")
+		return
+	}
+	fmt.Printf("\n%d tracks unchanged\n", diff.Unchanged)
+}
@@ -0,0 +1,137 @@
+package command
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/rocketbang/spotify-controller/spotify"
+	"github.com/rocketbang/spotify-controller/tracklist"
+)
+
+type importMatch struct {
+	Entry      *tracklist.Entry
+	URI        string
+	Label      string
+	Confidence float64
+}
+
+func importPlaylist(args string) {
+	fields := splitQuoted(args)
+	if len(fields) == 0 {
+		// The whole line is the path so paths with spaces don't need quotes
+		fmt.Println("Enter file to import:")
+		path := strings.Trim(strings.TrimSpace(getString("")), "\"")
+		if path == "" {
+			fmt.Println("No file given")
+			return
+		}
+		fields = []string{path}
+	}
+
+	path := fields[0]
+	playlistName := strings.Join(fields[1:], " ")
+	if playlistName == "" {
+		playlistName = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+
+	entries, err := tracklist.ReadFile(path)
+	if err != nil {
+		fmt.Printf("Could not read %s: %s\n", path, err.Error())
+		return
+	}
+	if len(entries) == 0 {
+		fmt.Printf("No tracks found in %s\n", path)
+		return
+	}
+
+	fmt.Printf("Matching %d tracks...\n", len(entries))
+
+	songURIs := make([]string, 0, len(entries))
+	unmatched := make([]*tracklist.Entry, 0)
+	for _, entry := range entries {
+		match := matchEntry(entry)
+		if match == nil {
+			unmatched = append(unmatched, entry)
+			continue
+		}
+		if match.Confidence < 1 {
+			fmt.Printf("Row %d: %s matched %s (%.0f%%)\n", entry.Row, entry.Label(), match.Label, match.Confidence*100)
+		}
+		songURIs = append(songURIs, match.URI)
+	}
+
+	if len(unmatched) > 0 {
+		fmt.Printf("\nCould not match %d tracks:\n", len(unmatched))
+		for _, entry := range unmatched {
+			fmt.Printf("Row %d: %s\n", entry.Row, entry.Label())
+		}
+	}
+
+	if len(songURIs) == 0 {
+		fmt.Println("No tracks were matched")
+		return
+	}
+
+	fmt.Printf("\nCreate %s with %d of %d tracks? (y/n)\n", playlistName, len(songURIs), len(entries))
+	if !getConfirm() {
+		return
+	}
+
+	_, err = createPlaylistWithTracks(playlistName, songURIs)
+	if err != nil {
+		fmt.Println(err.Error())
+	}
+}
+
+// matchEntry resolves the entry to a spotify track, returns nil if no track is a close enough match
+func matchEntry(entry *tracklist.Entry) *importMatch {
+	if uri := tracklist.TrackURI(entry.URI); uri != "" {
+		return &importMatch{Entry: entry, URI: uri, Label: uri, Confidence: 1}
+	}
+
+	if entry.ISRC != "" {
+		track := spotify.SearchTrackByISRC(entry.ISRC)
+		if track != nil {
+			return &importMatch{Entry: entry, URI: track.URI, Label: trackLabel(track), Confidence: 1}
+		}
+	}
+
+	if entry.Title == "" {
+		return nil
+	}
+
+	queries := []string{fmt.Sprintf("track:\"%s\"", entry.Title)}
+	if entry.Artist != "" {
+		queries[0] += fmt.Sprintf(" artist:\"%s\"", entry.Artist)
+		queries = append(queries, entry.Title+" "+entry.Artist)
+	}
+
+	var best *importMatch
+	for _, query := range queries {
+		for _, track := range spotify.SearchTracks(query, 5) {
+			artists := make([]string, len(track.Artists))
+			for i, artist := range track.Artists {
+				artists[i] = artist.Name
+			}
+
+			score := tracklist.Score(entry, track.Name, artists, track.DurationMs/1000)
+			if best == nil || score > best.Confidence {
+				best = &importMatch{Entry: entry, URI: track.URI, Label: trackLabel(track), Confidence: score}
+			}
+		}
+
+		if best != nil && best.Confidence >= tracklist.MinConfidence {
+			return best
+		}
+	}
+
+	return nil
+}
+
+func trackLabel(track *spotify.Track) string {
+	if len(track.Artists) == 0 {
+		return track.Name
+	}
+	return track.Name + " - " + track.Artists[0].Name
+}
@@ -0,0 +1,42 @@
+package command
+
+import (
+	"image"
+
+	"github.com/rocketbang/spotify-controller/cover"
+	"github.com/rocketbang/spotify-controller/spotify"
+)
+
+// setMosaicCover makes a cover from the album art of the first few albums in items and the
+// playlist name, then uploads it to the playlist
+func setMosaicCover(playlistID string, name string, items []*spotify.PlaylistTrackResItem) error {
+	tiles := make([]image.Image, 0, 9)
+	seen := make(map[string]bool)
+	for _, item := range items {
+		if len(tiles) == 9 {
+			break
+		}
+		album := item.Track.Album
+		if len(album.Images) == 0 || seen[album.ID] {
+			continue
+		}
+		seen[album.ID] = true
+
+		// Images are largest first, the medium one is big enough for a tile
+		url := album.Images[0].URL
+		if len(album.Images) > 1 {
+			url = album.Images[1].URL
+		}
+		img, err := cover.Fetch(spotify.Client, url)
+		if err != nil {
+			continue
+		}
+		tiles = append(tiles, img)
+	}
+
+	jpegImage, err := cover.Fit(cover.Mosaic(tiles, name), spotify.MaxCoverSize)
+	if err != nil {
+		return err
+	}
+	return spotify.UploadPlaylistCover(playlistID, jpegImage)
+}
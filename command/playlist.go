@@ -0,0 +1,192 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rocketbang/spotify-controller/spotify"
+)
+
+var playlistHelp = "Use playlist [list] to see your playlists with their owner, track count and visibility\n" +
+	"Use playlist new <name> to create an empty playlist, add --public to make it public\n" +
+	"Use playlist rename [playlist] [to <name>] or playlist describe [playlist] [to <description>]\n" +
+	"Put quotes around names with \"to\" in them, e.g. playlist rename Mix to \"Back to Black\"\n" +
+	"Use playlist public|private|collab [playlist] to change who can see and edit it\n" +
+	"Use playlist delete [playlist] to remove it from your library"
+
+func playlistCommand(args string) {
+	flags, rest := splitFlags(args)
+	fields := strings.Fields(rest)
+	if len(fields) == 0 {
+		listPlaylists()
+		return
+	}
+
+	action := strings.ToLower(fields[0])
+	rest = strings.TrimSpace(strings.TrimPrefix(rest, fields[0]))
+
+	switch action {
+	case "list":
+		listPlaylists()
+	case "new":
+		newPlaylist(rest, flags["public"] != "")
+	case "rename", "describe":
+		changePlaylistText(action, rest)
+	case "public", "private", "collab":
+		changePlaylistVisibility(action, rest)
+	case "delete":
+		deletePlaylist(rest)
+	default:
+		fmt.Println(playlistHelp)
+	}
+}
+
+func listPlaylists() {
+	playlists := spotify.GetPlaylists()
+	if playlists == nil {
+		fmt.Println("Could not get playlists")
+		return
+	}
+
+	for i, playlist := range playlists {
+		owner := playlist.OwnerName
+		if owner == "" {
+			owner = playlist.OwnerID
+		}
+		fmt.Printf("%d. %s - %s, %d tracks, %s\n", i+1, playlist.Name, owner, playlist.TrackCount, playlistVisibility(playlist))
+	}
+}
+
+func playlistVisibility(playlist *spotify.Playlist) string {
+	if playlist.Collaborative {
+		return "collaborative"
+	}
+	if playlist.Public {
+		return "public"
+	}
+	return "private"
+}
+
+func newPlaylist(name string, public bool) {
+	if name == "" {
+		fmt.Println("Enter new playlist name (New Playlist)")
+		name = getString("New Playlist")
+	}
+
+	userID, err := spotify.GetUserID()
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	_, err = spotify.CreateNewPlaylist(userID, name, public)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+	fmt.Printf("Created %s\n", name)
+}
+
+// changePlaylistText renames or describes a playlist, args are written as [playlist] [to <text>]
+//
+// A "to" inside quotes is part of the name, if there is more than one outside quotes the text is asked for
+func changePlaylistText(action string, args string) {
+	fields := splitFields(args)
+	toIndexes := make([]int, 0)
+	for i, field := range fields {
+		if strings.ToLower(field) == "to" {
+			toIndexes = append(toIndexes, i)
+		}
+	}
+
+	playlistName := strings.Join(fields, " ")
+	text := ""
+	if len(toIndexes) > 0 {
+		playlistName = strings.Join(fields[:toIndexes[0]], " ")
+	}
+	if len(toIndexes) == 1 {
+		text = strings.Join(fields[toIndexes[0]+1:], " ")
+	} else if len(toIndexes) > 1 {
+		fmt.Println("\"to\" is in there more than once, put quotes around names that contain it")
+	}
+	playlistName = strings.Replace(playlistName, "\"", "", -1)
+	text = strings.Replace(text, "\"", "", -1)
+
+	playlist := findPlaylist(playlistName)
+	if playlist == nil {
+		return
+	}
+
+	details := &spotify.PlaylistDetails{}
+	if action == "rename" {
+		if text == "" {
+			fmt.Printf("Enter new name for %s\n", playlist.Name)
+			text = strings.TrimSpace(getString(""))
+		}
+		if text == "" {
+			fmt.Println("The name can't be empty")
+			return
+		}
+		details.Name = &text
+	} else {
+		if text == "" {
+			fmt.Printf("Enter new description for %s\n", playlist.Name)
+			text = strings.TrimSpace(getString(""))
+		}
+		details.Description = &text
+	}
+
+	err := spotify.ChangePlaylistDetails(playlist.ID, details)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+	if action == "rename" {
+		fmt.Printf("Renamed %s to %s\n", playlist.Name, text)
+	} else {
+		fmt.Printf("Changed the description of %s\n", playlist.Name)
+	}
+}
+
+func changePlaylistVisibility(action string, playlistName string) {
+	playlist := findPlaylist(playlistName)
+	if playlist == nil {
+		return
+	}
+
+	// Spotify only allows collaborative playlists that are private
+	public := action == "public"
+	collaborative := action == "collab"
+	err := spotify.ChangePlaylistDetails(playlist.ID, &spotify.PlaylistDetails{Public: &public, Collaborative: &collaborative})
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	playlist.Public = public
+	playlist.Collaborative = collaborative
+	fmt.Printf("%s is now %s\n", playlist.Name, playlistVisibility(playlist))
+}
+
+func deletePlaylist(playlistName string) {
+	playlist := findPlaylist(playlistName)
+	if playlist == nil {
+		return
+	}
+
+	fmt.Printf("Delete %s with %d tracks? (y/n)\n", playlist.Name, playlist.TrackCount)
+	if !getConfirm() {
+		return
+	}
+
+	if !backupBeforeChange(playlist.ID, "delete") {
+		return
+	}
+
+	err := spotify.UnfollowPlaylist(playlist.ID)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+	fmt.Printf("Deleted %s, its tracks are kept in its backups\n", playlist.Name)
+}
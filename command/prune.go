@@ -0,0 +1,93 @@
+package command
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/rocketbang/spotify-controller/history"
+	"github.com/rocketbang/spotify-controller/spotify"
+)
+
+var pruneHelp = "Use prune [playlist] to remove the tracks you keep skipping\n" +
+	"A track is pruned once it has been skipped within 30 seconds more than 2 times, use --within=<seconds> and --skips=N to change this\n" +
+	"Only skips while playing the playlist count, they are read from the local history, use record on to start recording them"
+
+func prunePlaylist(args string) {
+	flags, rest := splitFlags(args)
+
+	within, err := intFlag(flags, "within", 30)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+	maxSkips, err := intFlag(flags, "skips", 2)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	plays, err := history.Read()
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+	if len(plays) == 0 {
+		fmt.Println("No plays have been recorded yet, use record on to start recording them")
+		return
+	}
+
+	playlist := findPlaylist(rest)
+	if playlist == nil {
+		return
+	}
+
+	// Skipping a track on the radio or in another playlist says nothing about whether it belongs in this one
+	skips := history.QuickSkips(plays, playlist.URI, time.Duration(within)*time.Second)
+
+	items := spotify.GetTracksInPlaylist(playlist.ID)
+	if items == nil {
+		fmt.Println("Could not get playlist tracks")
+		return
+	}
+
+	uris := make([]string, 0)
+	positions := make([]int, 0)
+	listed := make(map[string]bool)
+	for position, item := range items {
+		if item == nil || skips[item.Track.URI] <= maxSkips {
+			continue
+		}
+		uris = append(uris, item.Track.URI)
+		positions = append(positions, position)
+
+		if !listed[item.Track.URI] {
+			listed[item.Track.URI] = true
+			artist := ""
+			if len(item.Track.Artists) > 0 {
+				artist = " - " + item.Track.Artists[0].Name
+			}
+			fmt.Printf("%s%s, skipped %d times\n", item.Track.Name, artist, skips[item.Track.URI])
+		}
+	}
+
+	if len(uris) == 0 {
+		fmt.Printf("No tracks in %s have been skipped within %d seconds more than %d times\n", playlist.Name, within, maxSkips)
+		return
+	}
+
+	fmt.Printf("Remove these %d tracks from %s? (y/n)\n", len(listed), playlist.Name)
+	if !getConfirm() {
+		return
+	}
+
+	if !backupBeforeChange(playlist.ID, "prune") {
+		return
+	}
+
+	err = spotify.RemoveManyFromPlaylist(playlist.ID, uris, positions)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+	fmt.Printf("Removed %d tracks from %s\n", len(listed), playlist.Name)
+}
@@ -0,0 +1,116 @@
+package command
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rocketbang/spotify-controller/spotify"
+)
+
+// queueCheckInterval is how often playback is checked to see if the queue needs topping up
+const queueCheckInterval = 5 * time.Second
+
+// queueLowWater is how few queued tracks there can be before more are added
+const queueLowWater = 3
+
+// queueBatch is how many tracks are added to the queue at a time
+const queueBatch = 10
+
+var queueFeederMutex sync.Mutex
+var stopQueueFeeder chan struct{}
+
+// playAllTracks plays the tracks in order, even past the 800 track limit of PlayTracks
+//
+// The first tracks are played directly, the rest are added to the queue in the background as
+// playback reaches them. This stops once every track is queued or something else is played
+func playAllTracks(songURIs []string, playlistURI string) {
+	stopQueueFeeding()
+
+	played := spotify.PlayTracks(songURIs, playlistURI)
+	if played == 0 || played == len(songURIs) {
+		return
+	}
+
+	fmt.Printf("Playing the first %d tracks, the other %d will be queued as playback continues\n", played, len(songURIs)-played)
+
+	queueFeederMutex.Lock()
+	stop := make(chan struct{})
+	stopQueueFeeder = stop
+	queueFeederMutex.Unlock()
+
+	go feedQueue(songURIs, played, stop)
+}
+
+// stopQueueFeeding stops any tracks still waiting to be queued from a previous playAllTracks
+func stopQueueFeeding() {
+	queueFeederMutex.Lock()
+	defer queueFeederMutex.Unlock()
+
+	if stopQueueFeeder != nil {
+		close(stopQueueFeeder)
+		stopQueueFeeder = nil
+	}
+}
+
+func feedQueue(songURIs []string, played int, stop <-chan struct{}) {
+	positions := make(map[string][]int)
+	for i, uri := range songURIs {
+		positions[uri] = append(positions[uri], i)
+	}
+
+	ticker := time.NewTicker(queueCheckInterval)
+	defer ticker.Stop()
+
+	queued := played
+	current := 0
+	for queued < len(songURIs) {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		state, err := spotify.GetPlaybackState()
+		if err != nil {
+			continue
+		}
+
+		// Playing a playlist or album means the user has moved on
+		if state.ContextURI != "" {
+			return
+		}
+
+		next := nextPosition(positions[state.TrackURI], current)
+		if next < 0 {
+			return
+		}
+		current = next
+
+		// Queued tracks play straight after the current track, so wait until the directly played tracks are finished
+		if current < played-1 || queued-current > queueLowWater {
+			continue
+		}
+
+		end := queued + queueBatch
+		if end > len(songURIs) {
+			end = len(songURIs)
+		}
+		for _, uri := range songURIs[queued:end] {
+			if spotify.AddToQueue(uri) != nil {
+				return
+			}
+			queued++
+		}
+	}
+}
+
+// nextPosition returns the first position that isn't before current, or -1 if there is none
+func nextPosition(positions []int, current int) int {
+	for _, position := range positions {
+		if position >= current {
+			return position
+		}
+	}
+	return -1
+}
@@ -0,0 +1,191 @@
+package command
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"github.com/rocketbang/spotify-controller/spotify"
+)
+
+// recommendationTargets are the track attributes recommendations can aim for
+var recommendationTargets = []string{"acousticness", "danceability", "energy", "instrumentalness", "liveness", "loudness", "popularity", "speechiness", "tempo", "valence"}
+
+var targetsHelp = "Use --<attribute>=<value> to aim for tracks with that value, e.g. --energy=0.8 --tempo=120\n" +
+	"The attributes are " + strings.Join(recommendationTargets, ", ")
+
+// extendRounds is how many times extend asks for more recommendations when too many are already in the playlist
+const extendRounds = 5
+
+// targetFlags reads the recommendation target attributes from the flags
+func targetFlags(flags map[string]string) (map[string]float64, error) {
+	targets := make(map[string]float64)
+	for _, name := range recommendationTargets {
+		value, ok := flags[name]
+		if !ok {
+			continue
+		}
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("--%s must be a number", name)
+		}
+		targets[name] = number
+	}
+	return targets, nil
+}
+
+func trackIDFromURI(uri string) string {
+	if !strings.HasPrefix(uri, "spotify:track:") {
+		return ""
+	}
+	return strings.TrimPrefix(uri, "spotify:track:")
+}
+
+func radio(args string) {
+	flags, rest := splitFlags(args)
+	targets, err := targetFlags(flags)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	count := 20
+	if strings.TrimSpace(rest) != "" {
+		count, err = strconv.Atoi(strings.TrimSpace(rest))
+		if err != nil || count < 1 || count > 100 {
+			fmt.Println("The number of tracks must be from 1 to 100")
+			return
+		}
+	}
+
+	song := spotify.GetCurrentSong()
+	if song == nil {
+		fmt.Println("Could not get current song")
+		return
+	}
+
+	seeds := &spotify.RecommendationSeeds{}
+	if id := trackIDFromURI(song.URI); id != "" {
+		seeds.TrackIDs = append(seeds.TrackIDs, id)
+	}
+	if song.PrimaryArtistID != "" {
+		seeds.ArtistIDs = append(seeds.ArtistIDs, song.PrimaryArtistID)
+	}
+
+	tracks, err := spotify.GetRecommendations(seeds, targets, count)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	queued := 0
+	for _, track := range tracks {
+		if track.URI == song.URI {
+			continue
+		}
+		if spotify.AddToQueue(track.URI) != nil {
+			break
+		}
+		queued++
+	}
+	fmt.Printf("Queued %d tracks like %s\n", queued, song.Name)
+}
+
+func extendPlaylist(args string) {
+	flags, rest := splitFlags(args)
+	targets, err := targetFlags(flags)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	fields := strings.Fields(rest)
+	if len(fields) == 0 {
+		fmt.Println("Use extend [playlist] <number of tracks>")
+		return
+	}
+	count, err := strconv.Atoi(fields[len(fields)-1])
+	if err != nil || count < 1 {
+		fmt.Println("Use extend [playlist] <number of tracks>")
+		return
+	}
+
+	playlist := findPlaylist(strings.Join(fields[:len(fields)-1], " "))
+	if playlist == nil {
+		return
+	}
+
+	// Every track has to be known so none of them are recommended again
+	allItems, err := allPlaylistTracks(playlist)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+	items := nonNilItems(allItems)
+	seedIDs := make([]string, 0, len(items))
+	for _, item := range items {
+		if item.Track.ID != "" {
+			seedIDs = append(seedIDs, item.Track.ID)
+		}
+	}
+	if len(seedIDs) == 0 {
+		fmt.Printf("%s has no spotify tracks to base recommendations on\n", playlist.Name)
+		return
+	}
+
+	seen := trackKeySet(items)
+	added := make([]*spotify.Track, 0, count)
+	for round := 0; round < extendRounds && len(added) < count; round++ {
+		// Each round is seeded by a different random sample of the playlist
+		seeds := &spotify.RecommendationSeeds{}
+		for _, index := range rand.Perm(len(seedIDs)) {
+			if len(seeds.TrackIDs) == spotify.MaxRecommendationSeeds {
+				break
+			}
+			seeds.TrackIDs = append(seeds.TrackIDs, seedIDs[index])
+		}
+
+		tracks, err := spotify.GetRecommendations(seeds, targets, 100)
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+
+		for _, track := range tracks {
+			if len(added) == count {
+				break
+			}
+			if seen[track.ID] {
+				continue
+			}
+			seen[track.ID] = true
+			added = append(added, track)
+		}
+	}
+
+	if len(added) == 0 {
+		fmt.Println("Could not find any recommended tracks that aren't already in the playlist")
+		return
+	}
+
+	uris := make([]string, len(added))
+	for i, track := range added {
+		uris[i] = track.URI
+		artist := ""
+		if len(track.Artists) > 0 {
+			artist = " - " + track.Artists[0].Name
+		}
+		fmt.Printf("+ %s%s\n", track.Name, artist)
+	}
+
+	if !backupBeforeChange(playlist.ID, "extend") {
+		return
+	}
+	err = spotify.AddManyToPlaylist(playlist.ID, uris)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+	fmt.Printf("Added %d tracks to %s\n", len(uris), playlist.Name)
+}
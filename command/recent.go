@@ -0,0 +1,194 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rocketbang/spotify-controller/spotify"
+)
+
+var recentHelp = "Use recent to list your last 50 plays, add --limit=N to show fewer\n" +
+	"Use --before=<time> or --after=<time> to page through older or newer plays, the time can be a cursor shown by recent, a date, a time today or both, e.g. \"2024-03-01 18:30\"\n" +
+	"Use recent save <window> to make a playlist of what you listened to, e.g. recent save 3h, or use --after and --before to choose the window"
+
+var recentTimeLayouts = []string{"2006-01-02 15:04", "2006-01-02", "15:04"}
+
+func recentCommand(args string) {
+	flags, rest := splitFlags(args)
+	fields := strings.Fields(rest)
+
+	before, err := timeFlag(flags, "before")
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+	after, err := timeFlag(flags, "after")
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	if len(fields) > 0 && strings.ToLower(fields[0]) == "save" {
+		if len(fields) > 1 {
+			window, err := time.ParseDuration(fields[1])
+			if err != nil || window <= 0 {
+				fmt.Println("Could not read the window, use a length like 90m or 3h")
+				return
+			}
+			if before.IsZero() {
+				after = time.Now().Add(-window)
+			} else {
+				after = before.Add(-window)
+			}
+		}
+		if after.IsZero() {
+			fmt.Println(recentHelp)
+			return
+		}
+		saveRecentPlays(after, before, flags["name"])
+		return
+	}
+	if len(fields) > 0 {
+		fmt.Println(recentHelp)
+		return
+	}
+	if !before.IsZero() && !after.IsZero() {
+		fmt.Println("Use either --before or --after, not both")
+		return
+	}
+
+	limit, err := intFlag(flags, "limit", 50)
+	if err != nil || limit > 50 {
+		fmt.Println("--limit must be a number from 1 to 50")
+		return
+	}
+
+	plays, cursors := spotify.GetRecentlyPlayed(limit, before, after)
+	if plays == nil {
+		fmt.Println("Could not get recently played tracks")
+		return
+	}
+	if len(plays) == 0 {
+		fmt.Println("No plays found")
+		return
+	}
+
+	playlistNames := make(map[string]string)
+	for _, playlist := range spotify.GetPlaylists() {
+		playlistNames[playlist.URI] = playlist.Name
+	}
+
+	for _, play := range plays {
+		artist := ""
+		if len(play.Track.Artists) > 0 {
+			artist = " - " + play.Track.Artists[0].Name
+		}
+		fmt.Printf("%s  %s%s%s\n", play.PlayedAt.Local().Format("2006-01-02 15:04"), play.Track.Name, artist, playContext(play, playlistNames))
+	}
+
+	if cursors != nil && cursors.Before != "" {
+		fmt.Printf("\nUse recent --before=%s to see older plays\n", cursors.Before)
+	}
+}
+
+// playContext describes what the track was played from
+func playContext(play *spotify.PlayHistoryItem, playlistNames map[string]string) string {
+	if play.Context == nil || play.Context.URI == "" {
+		return ""
+	}
+	if name, ok := playlistNames[play.Context.URI]; ok {
+		return fmt.Sprintf(" (from %s %s)", play.Context.Type, name)
+	}
+	return fmt.Sprintf(" (from %s)", play.Context.Type)
+}
+
+// timeFlag reads a time flag, either a millisecond cursor from spotify or a local date and time
+func timeFlag(flags map[string]string, name string) (time.Time, error) {
+	value, ok := flags[name]
+	if !ok {
+		return time.Time{}, nil
+	}
+
+	if cursor, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(0, cursor*int64(time.Millisecond)), nil
+	}
+
+	for _, layout := range recentTimeLayouts {
+		parsed, err := time.ParseInLocation(layout, value, time.Local)
+		if err != nil {
+			continue
+		}
+		if layout == "15:04" {
+			now := time.Now()
+			parsed = time.Date(now.Year(), now.Month(), now.Day(), parsed.Hour(), parsed.Minute(), 0, 0, time.Local)
+		}
+		return parsed, nil
+	}
+	return time.Time{}, errors.New("Could not read --" + name + ", use a cursor, \"2006-01-02 15:04\", 2006-01-02 or 15:04")
+}
+
+// saveRecentPlays makes a playlist of the tracks played between after and before, in the order they were played
+func saveRecentPlays(after time.Time, before time.Time, playlistName string) {
+	end := before
+	if end.IsZero() {
+		end = time.Now()
+	}
+
+	// Spotify only returns 50 plays at a time, so page back from the end of the window until its start
+	plays := make([]*spotify.PlayHistoryItem, 0)
+	for {
+		page, _ := spotify.GetRecentlyPlayed(50, end, time.Time{})
+		if page == nil {
+			fmt.Println("Could not get recently played tracks")
+			return
+		}
+		if len(page) == 0 {
+			break
+		}
+
+		reachedStart := false
+		for _, play := range page {
+			if play.PlayedAt.Before(after) {
+				reachedStart = true
+				break
+			}
+			plays = append(plays, play)
+		}
+		if reachedStart {
+			break
+		}
+		end = page[len(page)-1].PlayedAt
+	}
+
+	if len(plays) == 0 {
+		fmt.Println("No plays found in that window, spotify only keeps a limited listening history")
+		return
+	}
+
+	uris := make([]string, 0, len(plays))
+	seen := make(map[string]bool)
+	for i := len(plays) - 1; i >= 0; i-- {
+		uri := plays[i].Track.URI
+		if !seen[uri] {
+			seen[uri] = true
+			uris = append(uris, uri)
+		}
+	}
+
+	if playlistName == "" || playlistName == "true" {
+		start := plays[len(plays)-1].PlayedAt.Local()
+		finish := plays[0].PlayedAt.Local()
+		playlistName = fmt.Sprintf("Listened %s %s–%s", start.Format("2006-01-02"), start.Format("15:04"), finish.Format("15:04"))
+		if start.YearDay() != finish.YearDay() || start.Year() != finish.Year() {
+			playlistName = fmt.Sprintf("Listened %s – %s", start.Format("2006-01-02 15:04"), finish.Format("2006-01-02 15:04"))
+		}
+	}
+
+	_, err := createPlaylistWithTracks(playlistName, uris)
+	if err != nil {
+		fmt.Println(err.Error())
+	}
+}
@@ -0,0 +1,35 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rocketbang/spotify-controller/history"
+)
+
+func recordCommand(args string) {
+	switch strings.ToLower(strings.TrimSpace(args)) {
+	case "on":
+		history.StartRecording()
+		fmt.Println("Recording plays in the background")
+	case "off":
+		history.StopRecording()
+		fmt.Println("Stopped recording plays")
+	case "", "status":
+		plays, err := history.Read()
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+		status := "off"
+		if history.Recording() {
+			status = "on"
+		}
+		fmt.Printf("Recording is %s, %d plays recorded\n", status, len(plays))
+		if len(plays) > 0 {
+			fmt.Printf("First play recorded %s\n", plays[0].StartedAt.Local().Format("2006-01-02 15:04"))
+		}
+	default:
+		fmt.Println("Use record on, record off or record status")
+	}
+}
@@ -0,0 +1,202 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rocketbang/spotify-controller/spotify"
+)
+
+var setOperationHelp = "Use %s <playlist A> <playlist B> -> <new playlist>, quote names with spaces\n" +
+	"Use --dedupe to only keep the first copy of each track\n" +
+	"Use --shuffle to shuffle the result instead of keeping the order of the source playlists, --mode and --seed work as they do for shuffle\n" +
+	"Use --existing to add the tracks to an existing playlist instead of creating a new one\n" +
+	"Use --cover to give a new playlist a cover made from its album art"
+
+func mergePlaylists(args string) {
+	runSetOperation("merge", args, func(a []*spotify.PlaylistTrackResItem, b []*spotify.PlaylistTrackResItem) []*spotify.PlaylistTrackResItem {
+		return append(append([]*spotify.PlaylistTrackResItem{}, a...), b...)
+	})
+}
+
+func intersectPlaylists(args string) {
+	runSetOperation("intersect", args, func(a []*spotify.PlaylistTrackResItem, b []*spotify.PlaylistTrackResItem) []*spotify.PlaylistTrackResItem {
+		inB := trackKeySet(b)
+		result := make([]*spotify.PlaylistTrackResItem, 0)
+		for _, item := range a {
+			if inB[trackKey(item)] {
+				result = append(result, item)
+			}
+		}
+		return result
+	})
+}
+
+func subtractPlaylists(args string) {
+	runSetOperation("subtract", args, func(a []*spotify.PlaylistTrackResItem, b []*spotify.PlaylistTrackResItem) []*spotify.PlaylistTrackResItem {
+		inB := trackKeySet(b)
+		result := make([]*spotify.PlaylistTrackResItem, 0)
+		for _, item := range a {
+			if !inB[trackKey(item)] {
+				result = append(result, item)
+			}
+		}
+		return result
+	})
+}
+
+func runSetOperation(name string, args string, operation func([]*spotify.PlaylistTrackResItem, []*spotify.PlaylistTrackResItem) []*spotify.PlaylistTrackResItem) {
+	flags, rest := splitFlags(args)
+	strategy, random, err := getShuffleStrategy(flags)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	sourceNames := make([]string, 0)
+	targetName := ""
+	if arrow := strings.Index(rest, "->"); arrow >= 0 {
+		sourceNames = splitQuoted(rest[:arrow])
+		targetName = strings.Trim(strings.TrimSpace(rest[arrow+2:]), "\"")
+	} else {
+		sourceNames = splitQuoted(rest)
+	}
+	if len(sourceNames) > 2 {
+		fmt.Printf("Too many playlists given. "+setOperationHelp+"\n", name)
+		return
+	}
+	for len(sourceNames) < 2 {
+		sourceNames = append(sourceNames, "")
+	}
+
+	sources := make([]*spotify.Playlist, 2)
+	items := make([][]*spotify.PlaylistTrackResItem, 2)
+	for i, sourceName := range sourceNames {
+		if sourceName == "" {
+			fmt.Printf("Choose playlist %c\n", 'A'+i)
+		}
+		sources[i] = findPlaylist(sourceName)
+		if sources[i] == nil {
+			return
+		}
+
+		sourceItems, err := allPlaylistTracks(sources[i])
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+		items[i] = nonNilItems(sourceItems)
+	}
+
+	result := operation(items[0], items[1])
+
+	var target *spotify.Playlist
+	if flags["existing"] != "" {
+		if targetName == "" {
+			fmt.Println("Choose playlist to add the tracks to")
+		}
+		target = findPlaylist(targetName)
+		if target == nil {
+			return
+		}
+	} else if targetName == "" {
+		defaultName := fmt.Sprintf("%s %s %s", sources[0].Name, name, sources[1].Name)
+		fmt.Printf("Enter new playlist name (%s)\n", defaultName)
+		targetName = getString(defaultName)
+	}
+
+	if flags["dedupe"] != "" {
+		var existing []*spotify.PlaylistTrackResItem
+		if target != nil {
+			targetItems, err := allPlaylistTracks(target)
+			if err != nil {
+				fmt.Println(err.Error())
+				return
+			}
+			existing = nonNilItems(targetItems)
+		}
+		result = dedupeItems(result, existing)
+	}
+
+	var uris []string
+	if flags["shuffle"] != "" {
+		uris = shuffledURIs(result, strategy, random)
+	} else {
+		uris = make([]string, len(result))
+		for i, item := range result {
+			uris[i] = item.Track.URI
+		}
+	}
+
+	if len(uris) == 0 {
+		fmt.Printf("No tracks left after %s of %s and %s\n", name, sources[0].Name, sources[1].Name)
+		return
+	}
+
+	if target == nil {
+		playlistID, err := createPlaylistWithTracks(targetName, uris)
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+		if flags["cover"] != "" {
+			err = setMosaicCover(playlistID, targetName, result)
+			if err != nil {
+				fmt.Println(err.Error())
+			}
+		}
+		return
+	}
+
+	if !backupBeforeChange(target.ID, name) {
+		return
+	}
+	err = spotify.AddManyToPlaylist(target.ID, uris)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+	fmt.Printf("Added %d tracks to %s\n", len(uris), target.Name)
+}
+
+// trackKey identifies a track, local files have no ID so they use their URI
+func trackKey(item *spotify.PlaylistTrackResItem) string {
+	if item.Track.ID != "" {
+		return item.Track.ID
+	}
+	return item.Track.URI
+}
+
+func trackKeySet(items []*spotify.PlaylistTrackResItem) map[string]bool {
+	set := make(map[string]bool, len(items))
+	for _, item := range items {
+		set[trackKey(item)] = true
+	}
+	return set
+}
+
+// dedupeItems keeps the first copy of each track, dropping any track that is in existing
+func dedupeItems(items []*spotify.PlaylistTrackResItem, existing []*spotify.PlaylistTrackResItem) []*spotify.PlaylistTrackResItem {
+	seen := trackKeySet(existing)
+	result := make([]*spotify.PlaylistTrackResItem, 0, len(items))
+	for _, item := range items {
+		key := trackKey(item)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, item)
+	}
+	return result
+}
+
+// nonNilItems drops any items that could not be fetched
+func nonNilItems(items []*spotify.PlaylistTrackResItem) []*spotify.PlaylistTrackResItem {
+	result := make([]*spotify.PlaylistTrackResItem, 0, len(items))
+	for _, item := range items {
+		if item != nil && item.Track.URI != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
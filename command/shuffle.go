@@ -0,0 +1,193 @@
+package command
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+
+	"github.com/rocketbang/spotify-controller/shuffle"
+	"github.com/rocketbang/spotify-controller/spotify"
+)
+
+var shuffleFlagsHelp = "Use --mode=<" + strings.Join(shuffle.Names(), "|") + "> to choose how tracks are shuffled\n" +
+	"artist and album space out tracks by the same artist or album, fresh tends to play recently added tracks first\n" +
+	"Use --seed=<value> to get the same shuffle every time"
+
+func shuffleCommand(args string) {
+	flags, rest := splitFlags(args)
+	if flags["in-place"] != "" {
+		shuffleInPlace(rest, flags)
+		return
+	}
+	shuffleInNewPlaylist(rest, flags)
+}
+
+// getShuffleStrategy reads the --mode and --seed flags
+func getShuffleStrategy(flags map[string]string) (shuffle.Strategy, *rand.Rand, error) {
+	strategy, err := shuffle.Get(flags["mode"])
+	if err != nil {
+		return nil, nil, err
+	}
+	return strategy, shuffle.NewRand(flags["seed"]), nil
+}
+
+// shuffleOrder returns the new order of the items as indexes into items
+func shuffleOrder(items []*spotify.PlaylistTrackResItem, strategy shuffle.Strategy, random *rand.Rand) []int {
+	return strategy(toShuffleTracks(items), random)
+}
+
+func toShuffleTracks(items []*spotify.PlaylistTrackResItem) []shuffle.Track {
+	tracks := make([]shuffle.Track, len(items))
+	for i, item := range items {
+		if item == nil {
+			continue
+		}
+
+		tracks[i] = toShuffleTrack(&item.Track)
+		tracks[i].AddedAt = item.AddedAt
+	}
+	return tracks
+}
+
+func toShuffleTrack(track *spotify.Track) shuffle.Track {
+	shuffleTrack := shuffle.Track{
+		URI:   track.URI,
+		Name:  track.Name,
+		Album: track.Album.ID,
+	}
+	if len(track.Artists) > 0 {
+		shuffleTrack.Artist = track.Artists[0].ID
+	}
+	// Local files have no ids so fall back to the names
+	if shuffleTrack.Album == "" {
+		shuffleTrack.Album = track.Album.Name
+	}
+	if shuffleTrack.Artist == "" && len(track.Artists) > 0 {
+		shuffleTrack.Artist = track.Artists[0].Name
+	}
+	return shuffleTrack
+}
+
+// shuffledURIs returns the track URIs of the items in a shuffled order
+func shuffledURIs(items []*spotify.PlaylistTrackResItem, strategy shuffle.Strategy, random *rand.Rand) []string {
+	uris := make([]string, 0, len(items))
+	for _, index := range shuffleOrder(items, strategy, random) {
+		if items[index] != nil {
+			uris = append(uris, items[index].Track.URI)
+		}
+	}
+	return uris
+}
+
+// shuffleInPlace gives the playlist a new random order while keeping it the same playlist
+//
+// Tracks are moved one run at a time with the reorder endpoint so their added dates are kept.
+// The fast option replaces the whole track list instead, which resets the added dates
+func shuffleInPlace(playlistName string, flags map[string]string) {
+	fast := flags["fast"] != ""
+	strategy, random, err := getShuffleStrategy(flags)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	if playlistName == "" {
+		fmt.Println("Choose playlist to shuffle")
+	}
+	playlist := findPlaylist(playlistName)
+	if playlist == nil {
+		return
+	}
+
+	items := spotify.GetTracksInPlaylist(playlist.ID)
+	if items == nil {
+		fmt.Println("Could not get playlist tracks")
+		return
+	}
+	if len(items) < 2 {
+		fmt.Printf("%s is too short to shuffle\n", playlist.Name)
+		return
+	}
+
+	order := shuffleOrder(items, strategy, random)
+
+	if !backupBeforeChange(playlist.ID, "shuffle") {
+		return
+	}
+
+	if fast {
+		uris := make([]string, len(order))
+		for i, index := range order {
+			if items[index] == nil || strings.HasPrefix(items[index].Track.URI, "spotify:local:") {
+				fmt.Println("Playlist has local files which can't be re-added, shuffling without --fast")
+				fast = false
+				break
+			}
+			uris[i] = items[index].Track.URI
+		}
+
+		if fast {
+			err = spotify.ReplacePlaylistTracks(playlist.ID, uris)
+			if err != nil {
+				fmt.Println(err.Error())
+				return
+			}
+			fmt.Printf("Shuffled %s\n", playlist.Name)
+			return
+		}
+	}
+
+	err = reorderPlaylist(playlist, order)
+	if err != nil {
+		fmt.Println(err.Error())
+		fmt.Println("Use restore to return the playlist to its previous order")
+		return
+	}
+	fmt.Printf("Shuffled %s\n", playlist.Name)
+}
+
+// reorderPlaylist moves the tracks of the playlist so the track at order[i] ends up at position i
+//
+// Each request uses the snapshot returned by the previous one so the positions always line up
+func reorderPlaylist(playlist *spotify.Playlist, order []int) error {
+	current := make([]int, len(order))
+	for i := range current {
+		current[i] = i
+	}
+
+	snapshotID := playlist.SnapshotID
+	moves := 0
+	for i := 0; i < len(order); i++ {
+		if current[i] == order[i] {
+			continue
+		}
+
+		from := i + 1
+		for current[from] != order[i] {
+			from++
+		}
+
+		// Move any following tracks that are already in the right order along with it
+		length := 1
+		for from+length < len(current) && i+length < len(order) && current[from+length] == order[i+length] {
+			length++
+		}
+
+		var err error
+		snapshotID, err = spotify.ReorderPlaylistTracks(playlist.ID, from, i, length, snapshotID)
+		if err != nil {
+			return err
+		}
+
+		moved := append([]int{}, current[from:from+length]...)
+		current = append(current[:from], current[from+length:]...)
+		current = append(current[:i], append(moved, current[i:]...)...)
+
+		moves++
+		if moves%50 == 0 {
+			fmt.Printf("Moved %d of %d tracks\n", i+length, len(order))
+		}
+		i += length - 1
+	}
+	return nil
+}
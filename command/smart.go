@@ -0,0 +1,480 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rocketbang/spotify-controller/config"
+	"github.com/rocketbang/spotify-controller/spotify"
+)
+
+// smartFilter is a parsed smart playlist filter rule
+type smartFilter struct {
+	Match         func(item *spotify.PlaylistTrackResItem, features *spotify.AudioFeatures) bool
+	NeedsFeatures bool
+}
+
+// filterOperators has the two character operators first so they're matched before the one character ones
+var filterOperators = []string{">=", "<=", "!=", "=", "<", ">"}
+
+// parseSmartFilter reads a rule such as "year >= 2000", "artist = Radiohead", "explicit = false" or "added within 30"
+func parseSmartFilter(rule string) (*smartFilter, error) {
+	if within := strings.Index(rule, " within "); within > 0 {
+		field := strings.ToLower(strings.TrimSpace(rule[:within]))
+		days, err := strconv.Atoi(strings.TrimSpace(rule[within+len(" within "):]))
+		if field != "added" || err != nil {
+			return nil, errors.New("Could not read filter " + rule + ", use added within <days>")
+		}
+		since := time.Now().AddDate(0, 0, -days)
+		return &smartFilter{Match: func(item *spotify.PlaylistTrackResItem, features *spotify.AudioFeatures) bool {
+			return item.AddedAt.After(since)
+		}}, nil
+	}
+
+	// Use the operator that comes first in the rule, so values such as "title = <3" are kept whole
+	operator := ""
+	index := -1
+	for i := 1; i < len(rule) && operator == ""; i++ {
+		for _, candidate := range filterOperators {
+			if strings.HasPrefix(rule[i:], candidate) {
+				operator = candidate
+				index = i
+				break
+			}
+		}
+	}
+	if operator == "" {
+		return nil, errors.New("Could not read filter " + rule + ", use <field> <operator> <value>")
+	}
+
+	field := strings.ToLower(strings.TrimSpace(rule[:index]))
+	value := strings.TrimSpace(rule[index+len(operator):])
+
+	switch field {
+	case "artist", "album", "title":
+		if operator != "=" && operator != "!=" {
+			return nil, errors.New(field + " filters can only use = or !=")
+		}
+		return &smartFilter{Match: func(item *spotify.PlaylistTrackResItem, features *spotify.AudioFeatures) bool {
+			names := []string{item.Track.Name}
+			if field == "album" {
+				names = []string{item.Track.Album.Name}
+			} else if field == "artist" {
+				names = make([]string, len(item.Track.Artists))
+				for i, artist := range item.Track.Artists {
+					names[i] = artist.Name
+				}
+			}
+
+			found := false
+			for _, name := range names {
+				if strings.EqualFold(name, value) {
+					found = true
+				}
+			}
+			return found == (operator == "=")
+		}}, nil
+	case "explicit":
+		explicit, err := strconv.ParseBool(value)
+		if err != nil || (operator != "=" && operator != "!=") {
+			return nil, errors.New("Use explicit = true or explicit = false")
+		}
+		return &smartFilter{Match: func(item *spotify.PlaylistTrackResItem, features *spotify.AudioFeatures) bool {
+			return (item.Track.Explicit == explicit) == (operator == "=")
+		}}, nil
+	}
+
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, errors.New("Could not read the number in filter " + rule)
+	}
+
+	var getValue func(item *spotify.PlaylistTrackResItem, features *spotify.AudioFeatures) (float64, bool)
+	needsFeatures := false
+	switch field {
+	case "year":
+		getValue = func(item *spotify.PlaylistTrackResItem, features *spotify.AudioFeatures) (float64, bool) {
+			if len(item.Track.Album.ReleaseDate) < 4 {
+				return 0, false
+			}
+			year, err := strconv.Atoi(item.Track.Album.ReleaseDate[:4])
+			return float64(year), err == nil
+		}
+	case "duration":
+		getValue = func(item *spotify.PlaylistTrackResItem, features *spotify.AudioFeatures) (float64, bool) {
+			return float64(item.Track.DurationMs) / 1000, true
+		}
+	default:
+		sortField, ok := sortFields[field]
+		if !ok || sortField.Number == nil {
+			return nil, errors.New("Unknown filter field " + field)
+		}
+		getValue = sortField.Number
+		needsFeatures = sortField.NeedsFeatures
+	}
+
+	return &smartFilter{
+		Match: func(item *spotify.PlaylistTrackResItem, features *spotify.AudioFeatures) bool {
+			actual, ok := getValue(item, features)
+			if !ok {
+				return false
+			}
+			switch operator {
+			case ">=":
+				return actual >= number
+			case "<=":
+				return actual <= number
+			case "!=":
+				return actual != number
+			case "=":
+				return actual == number
+			case "<":
+				return actual < number
+			}
+			return actual > number
+		},
+		NeedsFeatures: needsFeatures,
+	}, nil
+}
+
+// lookupPlaylist finds a playlist by ID or exact name without asking the user
+func lookupPlaylist(playlists []*spotify.Playlist, nameOrID string) *spotify.Playlist {
+	for _, playlist := range playlists {
+		if playlist.ID == nameOrID || strings.EqualFold(playlist.Name, nameOrID) {
+			return playlist
+		}
+	}
+	return nil
+}
+
+// smartSourceItems gets the tracks from a smart playlist source
+//
+// An error is returned if the source couldn't be fetched, syncing to an empty list would empty the playlist
+func smartSourceItems(source string, playlists []*spotify.Playlist) ([]*spotify.PlaylistTrackResItem, error) {
+	kind := source
+	arg := ""
+	if colon := strings.Index(source, ":"); colon >= 0 {
+		kind = source[:colon]
+		arg = strings.TrimSpace(source[colon+1:])
+	}
+
+	switch strings.ToLower(kind) {
+	case "playlist":
+		playlist := lookupPlaylist(playlists, arg)
+		if playlist == nil {
+			return nil, errors.New("Could not find playlist " + arg)
+		}
+		items, err := allPlaylistTracks(playlist)
+		if err != nil {
+			return nil, err
+		}
+		return nonNilItems(items), nil
+	case "liked":
+		items := spotify.GetLikedTracks()
+		if items == nil {
+			return nil, errors.New("Could not get liked songs")
+		}
+		for _, item := range items {
+			if item == nil {
+				return nil, errors.New("Could not get every liked song, they changed while loading")
+			}
+		}
+		return nonNilItems(items), nil
+	case "top":
+		if arg == "" {
+			arg = "medium"
+		}
+		if topRangeNames[arg] == "" {
+			return nil, errors.New("Unknown top range " + arg + ", use short, medium or long")
+		}
+		tracks := spotify.GetTopTracks(arg)
+		if tracks == nil {
+			return nil, errors.New("Could not get top tracks")
+		}
+		items := make([]*spotify.PlaylistTrackResItem, len(tracks))
+		for i, track := range tracks {
+			items[i] = &spotify.PlaylistTrackResItem{Track: *track}
+		}
+		return items, nil
+	case "recent":
+		history, _ := spotify.GetRecentlyPlayed(50, time.Time{}, time.Time{})
+		if history == nil {
+			return nil, errors.New("Could not get recently played tracks")
+		}
+		items := make([]*spotify.PlaylistTrackResItem, len(history))
+		for i, play := range history {
+			items[i] = &spotify.PlaylistTrackResItem{Track: play.Track, AddedAt: play.PlayedAt}
+		}
+		return items, nil
+	}
+	return nil, errors.New("Unknown source " + source + ", use playlist:<name>, liked, top[:short|medium|long] or recent")
+}
+
+// buildSmartPlaylist returns the tracks the smart playlist should have, in order
+func buildSmartPlaylist(smart *config.SmartPlaylist, playlists []*spotify.Playlist) ([]*spotify.PlaylistTrackResItem, error) {
+	filters := make([]*smartFilter, len(smart.Filters))
+	needsFeatures := false
+	for i, rule := range smart.Filters {
+		filter, err := parseSmartFilter(rule)
+		if err != nil {
+			return nil, err
+		}
+		filters[i] = filter
+		needsFeatures = needsFeatures || filter.NeedsFeatures
+	}
+
+	var keys []*sortKey
+	if smart.Order != "" && smart.Order != "shuffle" {
+		var err error
+		keys, err = parseSortKeys(smart.Order)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	items := make([]*spotify.PlaylistTrackResItem, 0)
+	seen := make(map[string]bool)
+	for _, source := range smart.Sources {
+		sourceItems, err := smartSourceItems(source, playlists)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range sourceItems {
+			if item.Track.URI == "" || seen[trackKey(item)] {
+				continue
+			}
+			seen[trackKey(item)] = true
+			items = append(items, item)
+		}
+	}
+
+	var features map[string]*spotify.AudioFeatures
+	if needsFeatures {
+		ids := make([]string, 0, len(items))
+		for _, item := range items {
+			if item.Track.ID != "" {
+				ids = append(ids, item.Track.ID)
+			}
+		}
+		features = spotify.GetAudioFeatures(ids)
+		if features == nil {
+			return nil, errors.New("Could not get audio features")
+		}
+	}
+
+	matched := make([]*spotify.PlaylistTrackResItem, 0, len(items))
+	for _, item := range items {
+		keep := true
+		for _, filter := range filters {
+			if !filter.Match(item, features[item.Track.ID]) {
+				keep = false
+				break
+			}
+		}
+		if keep {
+			matched = append(matched, item)
+		}
+	}
+
+	if smart.Order == "shuffle" {
+		rand.Shuffle(len(matched), func(i, j int) { matched[i], matched[j] = matched[j], matched[i] })
+	} else if keys != nil {
+		order := sortOrder(matched, keys)
+		sorted := make([]*spotify.PlaylistTrackResItem, len(order))
+		for i, index := range order {
+			sorted[i] = matched[index]
+		}
+		matched = sorted
+	}
+
+	if smart.Limit > 0 && len(matched) > smart.Limit {
+		matched = matched[:smart.Limit]
+	}
+	return matched, nil
+}
+
+// syncSmartPlaylist changes the playlist to hold the given tracks, only adding and removing the tracks that differ
+//
+// Returns true if the playlist was changed
+func syncSmartPlaylist(playlist *spotify.Playlist, uris []string, ordered bool) (bool, error) {
+	// A missing page would make its tracks look missing and they'd be added again
+	items, err := allPlaylistTracks(playlist)
+	if err != nil {
+		return false, err
+	}
+
+	wanted := make(map[string]int)
+	for _, uri := range uris {
+		wanted[uri]++
+	}
+
+	removeURIs := make([]string, 0)
+	removePositions := make([]int, 0)
+	kept := make([]string, 0, len(items))
+	// remaining is every track left after the removals in playlist order, tracks with no URI such as
+	// unavailable ones are left where they are and held by an empty string so the positions stay right
+	remaining := make([]string, 0, len(items))
+	for position, item := range items {
+		if item.Track.URI == "" {
+			remaining = append(remaining, "")
+			continue
+		}
+		uri := item.Track.URI
+		if wanted[uri] > 0 {
+			wanted[uri]--
+			kept = append(kept, uri)
+			remaining = append(remaining, uri)
+			continue
+		}
+		removeURIs = append(removeURIs, uri)
+		removePositions = append(removePositions, position)
+	}
+
+	missing := make([]string, 0)
+	for _, uri := range uris {
+		if wanted[uri] > 0 {
+			wanted[uri]--
+			missing = append(missing, uri)
+		}
+	}
+
+	if len(removeURIs) == 0 && len(missing) == 0 && (!ordered || equalStrings(kept, uris)) {
+		fmt.Printf("%s is up to date\n", playlist.Name)
+		return false, nil
+	}
+
+	if !backupBeforeChange(playlist.ID, "smart sync") {
+		return false, nil
+	}
+
+	if len(removeURIs) > 0 {
+		err := spotify.RemoveManyFromPlaylist(playlist.ID, removeURIs, removePositions)
+		if err != nil {
+			return false, err
+		}
+	}
+	if len(missing) > 0 {
+		err := spotify.AddManyToPlaylist(playlist.ID, missing)
+		if err != nil {
+			return true, err
+		}
+	}
+	fmt.Printf("%s: added %d, removed %d\n", playlist.Name, len(missing), len(removeURIs))
+
+	if !ordered {
+		return true, nil
+	}
+
+	// Find where each wanted track now is so the playlist can be reordered to match
+	current := append(remaining, missing...)
+	positions := make(map[string][]int)
+	for i, uri := range current {
+		positions[uri] = append(positions[uri], i)
+	}
+	order := make([]int, 0, len(current))
+	for _, uri := range uris {
+		order = append(order, positions[uri][0])
+		positions[uri] = positions[uri][1:]
+	}
+	// Tracks with no URI go after the wanted tracks
+	order = append(order, positions[""]...)
+
+	// The snapshot changed when tracks were added or removed so use the latest one
+	return true, reorderPlaylist(&spotify.Playlist{ID: playlist.ID, Name: playlist.Name}, order)
+}
+
+func equalStrings(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func smartCommand(args string) {
+	fields := strings.Fields(args)
+	if len(fields) == 0 || (fields[0] != "sync" && fields[0] != "list") {
+		fmt.Println("Use smart list or smart sync [name]")
+		return
+	}
+
+	smartPlaylists := config.Value.SmartPlaylists
+	if len(smartPlaylists) == 0 {
+		fmt.Println("No smart playlists are set up, add them to smartPlaylists in config.json")
+		return
+	}
+
+	if fields[0] == "list" {
+		for _, smart := range smartPlaylists {
+			fmt.Printf("%s\n  sources: %s\n", smart.Name, strings.Join(smart.Sources, ", "))
+			if len(smart.Filters) > 0 {
+				fmt.Printf("  filters: %s\n", strings.Join(smart.Filters, ", "))
+			}
+			if smart.Order != "" {
+				fmt.Printf("  order: %s\n", smart.Order)
+			}
+			if smart.Limit > 0 {
+				fmt.Printf("  limit: %d\n", smart.Limit)
+			}
+		}
+		return
+	}
+
+	name := strings.Join(fields[1:], " ")
+
+	playlists := spotify.GetPlaylists()
+	if playlists == nil {
+		fmt.Println("Could not get playlists")
+		return
+	}
+
+	synced := 0
+	for i := range smartPlaylists {
+		smart := &smartPlaylists[i]
+		if name != "" && !strings.EqualFold(smart.Name, name) {
+			continue
+		}
+		synced++
+
+		fmt.Printf("Syncing %s\n", smart.Name)
+		items, err := buildSmartPlaylist(smart, playlists)
+		if err != nil {
+			fmt.Printf("Skipping %s: %s\n", smart.Name, err.Error())
+			continue
+		}
+		uris := make([]string, len(items))
+		for i, item := range items {
+			uris[i] = item.Track.URI
+		}
+
+		// The cover is only made again when the tracks change, as it means fetching every album image
+		playlistID := ""
+		changed := true
+		playlist := lookupPlaylist(playlists, smart.Name)
+		if playlist == nil {
+			playlistID, err = createPlaylistWithTracks(smart.Name, uris)
+		} else {
+			playlistID = playlist.ID
+			// A shuffled order would be different every time, so only new tracks are shuffled in
+			changed, err = syncSmartPlaylist(playlist, uris, smart.Order != "" && smart.Order != "shuffle")
+		}
+		if err == nil && changed && smart.Cover {
+			err = setMosaicCover(playlistID, smart.Name, items)
+		}
+		if err != nil {
+			fmt.Println(err.Error())
+		}
+	}
+
+	if synced == 0 {
+		fmt.Printf("No smart playlist called %s\n", name)
+	}
+}
@@ -0,0 +1,98 @@
+package command
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/rocketbang/spotify-controller/spotify"
+)
+
+// testItem decodes a playlist item the way the API would return it
+func testItem(t *testing.T, data string) *spotify.PlaylistTrackResItem {
+	t.Helper()
+	item := &spotify.PlaylistTrackResItem{}
+	if err := json.Unmarshal([]byte(data), item); err != nil {
+		t.Fatalf("could not decode test item: %v", err)
+	}
+	return item
+}
+
+func TestParseSmartFilter(t *testing.T) {
+	item := testItem(t, `{
+		"added_at": "`+time.Now().AddDate(0, 0, -10).UTC().Format(time.RFC3339)+`",
+		"track": {
+			"name": "<3 Song",
+			"explicit": true,
+			"duration_ms": 200000,
+			"popularity": 40,
+			"album": {"name": "Kid A", "release_date": "2000-10-02"},
+			"artists": [{"name": "Radiohead"}, {"name": "Someone Else"}]
+		}
+	}`)
+	features := &spotify.AudioFeatures{Energy: 0.8}
+
+	tests := []struct {
+		rule         string
+		wantMatch    bool
+		wantFeatures bool
+		wantErr      bool
+	}{
+		{rule: "year >= 2000", wantMatch: true},
+		{rule: "year > 2000", wantMatch: false},
+		{rule: "year<=1999", wantMatch: false},
+		{rule: "year != 1999", wantMatch: true},
+		{rule: "year = 2000", wantMatch: true},
+		{rule: "duration < 201", wantMatch: true},
+		{rule: "popularity > 30", wantMatch: true},
+		{rule: "energy >= 0.5", wantMatch: true, wantFeatures: true},
+		{rule: "Artist = radiohead", wantMatch: true},
+		{rule: "artist = Someone Else", wantMatch: true},
+		{rule: "artist != Radiohead", wantMatch: false},
+		{rule: "album = Kid A", wantMatch: true},
+		// The first operator is used, so the value can hold another one
+		{rule: "title = <3 Song", wantMatch: true},
+		{rule: "title != <3", wantMatch: true},
+		{rule: "explicit = true", wantMatch: true},
+		{rule: "explicit != true", wantMatch: false},
+		{rule: "added within 30", wantMatch: true},
+		{rule: "added within 5", wantMatch: false},
+		{rule: "artist > Radiohead", wantErr: true},
+		{rule: "explicit = maybe", wantErr: true},
+		{rule: "explicit < true", wantErr: true},
+		{rule: "year >= soon", wantErr: true},
+		{rule: "mood = happy", wantErr: true},
+		{rule: "colour > 3", wantErr: true},
+		{rule: "year 2000", wantErr: true},
+		{rule: "= 2000", wantErr: true},
+		{rule: "released within 30", wantErr: true},
+		{rule: "added within a month", wantErr: true},
+	}
+
+	for _, test := range tests {
+		filter, err := parseSmartFilter(test.rule)
+		if (err != nil) != test.wantErr {
+			t.Errorf("parseSmartFilter(%q) error = %v, want error %v", test.rule, err, test.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if match := filter.Match(item, features); match != test.wantMatch {
+			t.Errorf("%q matched %v, want %v", test.rule, match, test.wantMatch)
+		}
+		if filter.NeedsFeatures != test.wantFeatures {
+			t.Errorf("%q needs features %v, want %v", test.rule, filter.NeedsFeatures, test.wantFeatures)
+		}
+	}
+}
+
+func TestParseSmartFilterMissingYear(t *testing.T) {
+	filter, err := parseSmartFilter("year < 3000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filter.Match(testItem(t, `{"track": {"album": {"release_date": ""}}}`), nil) {
+		t.Error("a track without a release date should not match a year filter")
+	}
+}
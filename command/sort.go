@@ -0,0 +1,233 @@
+package command
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/rocketbang/spotify-controller/spotify"
+)
+
+// sortField is a value tracks can be sorted by, either Text or Number is set
+type sortField struct {
+	Text func(item *spotify.PlaylistTrackResItem) string
+	// Number returns false if the track has no value
+	Number        func(item *spotify.PlaylistTrackResItem, features *spotify.AudioFeatures) (float64, bool)
+	NeedsFeatures bool
+}
+
+type sortKey struct {
+	Name       string
+	Field      *sortField
+	Descending bool
+}
+
+func featureField(value func(features *spotify.AudioFeatures) float64) *sortField {
+	return &sortField{
+		Number: func(item *spotify.PlaylistTrackResItem, features *spotify.AudioFeatures) (float64, bool) {
+			if features == nil {
+				return 0, false
+			}
+			return value(features), true
+		},
+		NeedsFeatures: true,
+	}
+}
+
+var titleField = &sortField{Text: func(item *spotify.PlaylistTrackResItem) string { return item.Track.Name }}
+var releaseField = &sortField{Text: func(item *spotify.PlaylistTrackResItem) string { return item.Track.Album.ReleaseDate }}
+var addedField = &sortField{Text: func(item *spotify.PlaylistTrackResItem) string {
+	return item.AddedAt.UTC().Format("2006-01-02T15:04:05")
+}}
+
+var sortFields = map[string]*sortField{
+	"artist": &sortField{Text: func(item *spotify.PlaylistTrackResItem) string {
+		if len(item.Track.Artists) == 0 {
+			return ""
+		}
+		return item.Track.Artists[0].Name
+	}},
+	"album":        &sortField{Text: func(item *spotify.PlaylistTrackResItem) string { return item.Track.Album.Name }},
+	"title":        titleField,
+	"name":         titleField,
+	"release":      releaseField,
+	"release-date": releaseField,
+	"added":        addedField,
+	"added-at":     addedField,
+	"duration": &sortField{Number: func(item *spotify.PlaylistTrackResItem, features *spotify.AudioFeatures) (float64, bool) {
+		return float64(item.Track.DurationMs), true
+	}},
+	"popularity": &sortField{Number: func(item *spotify.PlaylistTrackResItem, features *spotify.AudioFeatures) (float64, bool) {
+		return float64(item.Track.Popularity), true
+	}},
+	"tempo":            featureField(func(features *spotify.AudioFeatures) float64 { return features.Tempo }),
+	"energy":           featureField(func(features *spotify.AudioFeatures) float64 { return features.Energy }),
+	"danceability":     featureField(func(features *spotify.AudioFeatures) float64 { return features.Danceability }),
+	"valence":          featureField(func(features *spotify.AudioFeatures) float64 { return features.Valence }),
+	"acousticness":     featureField(func(features *spotify.AudioFeatures) float64 { return features.Acousticness }),
+	"instrumentalness": featureField(func(features *spotify.AudioFeatures) float64 { return features.Instrumentalness }),
+	"liveness":         featureField(func(features *spotify.AudioFeatures) float64 { return features.Liveness }),
+	"speechiness":      featureField(func(features *spotify.AudioFeatures) float64 { return features.Speechiness }),
+	"loudness":         featureField(func(features *spotify.AudioFeatures) float64 { return features.Loudness }),
+}
+
+func sortFieldNames() []string {
+	names := make([]string, 0, len(sortFields))
+	for name := range sortFields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// parseSortKeys reads keys like "artist, release desc"
+func parseSortKeys(text string) ([]*sortKey, error) {
+	keys := make([]*sortKey, 0)
+	for _, part := range strings.Split(text, ",") {
+		fields := strings.Fields(strings.ToLower(part))
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) > 2 || (len(fields) == 2 && fields[1] != "asc" && fields[1] != "desc") {
+			return nil, fmt.Errorf("Could not read sort key %s, use <field> [asc|desc]", strings.TrimSpace(part))
+		}
+
+		field, ok := sortFields[fields[0]]
+		if !ok {
+			return nil, fmt.Errorf("Unknown sort field %s, use one of %s", fields[0], strings.Join(sortFieldNames(), ", "))
+		}
+		keys = append(keys, &sortKey{Name: fields[0], Field: field, Descending: len(fields) == 2 && fields[1] == "desc"})
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("No sort fields given, use one of %s", strings.Join(sortFieldNames(), ", "))
+	}
+	return keys, nil
+}
+
+// sortOrder returns the sorted order of the items as indexes into items
+func sortOrder(items []*spotify.PlaylistTrackResItem, keys []*sortKey) []int {
+	var features map[string]*spotify.AudioFeatures
+	for _, key := range keys {
+		if key.Field.NeedsFeatures {
+			ids := make([]string, 0, len(items))
+			for _, item := range items {
+				if item.Track.ID != "" {
+					ids = append(ids, item.Track.ID)
+				}
+			}
+			features = spotify.GetAudioFeatures(ids)
+			if features == nil {
+				fmt.Println("Could not get audio features, tracks will be sorted without them")
+			}
+			break
+		}
+	}
+
+	compare := func(a *spotify.PlaylistTrackResItem, b *spotify.PlaylistTrackResItem, key *sortKey) int {
+		result := 0
+		if key.Field.Text != nil {
+			result = strings.Compare(strings.ToLower(key.Field.Text(a)), strings.ToLower(key.Field.Text(b)))
+		} else {
+			valueA, okA := key.Field.Number(a, features[a.Track.ID])
+			valueB, okB := key.Field.Number(b, features[b.Track.ID])
+			// Tracks without a value always go last
+			if !okA || !okB {
+				if okA == okB {
+					return 0
+				}
+				if okA {
+					return -1
+				}
+				return 1
+			}
+			if valueA < valueB {
+				result = -1
+			} else if valueA > valueB {
+				result = 1
+			}
+		}
+
+		if key.Descending {
+			return -result
+		}
+		return result
+	}
+
+	order := make([]int, len(items))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		for _, key := range keys {
+			result := compare(items[order[i]], items[order[j]], key)
+			if result != 0 {
+				return result < 0
+			}
+		}
+		return false
+	})
+	return order
+}
+
+func sortPlaylist(args string) {
+	flags, rest := splitFlags(args)
+
+	by := strings.LastIndex(" "+rest, " by ")
+	if by < 0 {
+		fmt.Println("Use sort [playlist] by <field> [asc|desc], <field> [asc|desc]...")
+		return
+	}
+	playlistName := strings.TrimSpace(rest[:by])
+	keys, err := parseSortKeys(rest[by+3:])
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	playlist := findPlaylist(playlistName)
+	if playlist == nil {
+		return
+	}
+
+	items := spotify.GetTracksInPlaylist(playlist.ID)
+	if items == nil {
+		fmt.Println("Could not get playlist tracks")
+		return
+	}
+	for _, item := range items {
+		if item == nil {
+			fmt.Println("Could not get every track in the playlist")
+			return
+		}
+	}
+
+	order := sortOrder(items, keys)
+
+	if newName, ok := flags["new"]; ok {
+		if newName == "true" {
+			newName = playlist.Name + " (sorted)"
+		}
+		uris := make([]string, 0, len(order))
+		for _, index := range order {
+			uris = append(uris, items[index].Track.URI)
+		}
+		_, err = createPlaylistWithTracks(newName, uris)
+		if err != nil {
+			fmt.Println(err.Error())
+		}
+		return
+	}
+
+	if !backupBeforeChange(playlist.ID, "sort") {
+		return
+	}
+
+	err = reorderPlaylist(playlist, order)
+	if err != nil {
+		fmt.Println(err.Error())
+		fmt.Println("Use restore to return the playlist to its previous order")
+		return
+	}
+	fmt.Printf("Sorted %s\n", playlist.Name)
+}
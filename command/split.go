@@ -0,0 +1,230 @@
+package command
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/rocketbang/spotify-controller/spotify"
+)
+
+var splitHelp = "Use split [playlist] by <decade|artist|added|genre|chunks N>\n" +
+	"decade uses the album release date, added uses the year each track was added and genre uses the primary artist's first genre\n" +
+	"Use --name=<template> to name the new playlists, {source} is the playlist name and {group} is the decade, artist, year, genre or chunk number\n" +
+	"Use --min=N to skip groups with fewer than N tracks"
+
+// defaultSplitNames are the name templates used for each rule when --name isn't given
+var defaultSplitNames = map[string]string{
+	"decade": "{source} – {decade}",
+	"artist": "{source} – {artist}",
+	"added":  "{source} – {year}",
+	"genre":  "{source} – {genre}",
+	"chunks": "{source} – {n}",
+}
+
+// splitGroup is one of the playlists a split will create
+type splitGroup struct {
+	Name  string
+	Items []*spotify.PlaylistTrackResItem
+}
+
+func splitPlaylist(args string) {
+	flags, rest := splitFlags(args)
+
+	by := strings.LastIndex(" "+rest, " by ")
+	if by < 0 {
+		fmt.Println(splitHelp)
+		return
+	}
+	playlistName := strings.TrimSpace(rest[:by])
+	rule := strings.Fields(strings.ToLower(rest[by+3:]))
+	if len(rule) == 0 || defaultSplitNames[rule[0]] == "" {
+		fmt.Println(splitHelp)
+		return
+	}
+
+	chunks := 0
+	if rule[0] == "chunks" {
+		if len(rule) < 2 {
+			fmt.Println("Use split [playlist] by chunks N")
+			return
+		}
+		var err error
+		chunks, err = strconv.Atoi(rule[1])
+		if err != nil || chunks < 2 {
+			fmt.Println("The number of chunks must be 2 or more")
+			return
+		}
+	}
+
+	minTracks, err := intFlag(flags, "min", 1)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	template := flags["name"]
+	if template == "" {
+		template = defaultSplitNames[rule[0]]
+	}
+
+	playlist := findPlaylist(playlistName)
+	if playlist == nil {
+		return
+	}
+
+	allItems, err := allPlaylistTracks(playlist)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+	items := nonNilItems(allItems)
+	if len(items) == 0 {
+		fmt.Printf("%s has no tracks\n", playlist.Name)
+		return
+	}
+
+	var groupKey func(index int, item *spotify.PlaylistTrackResItem) string
+	switch rule[0] {
+	case "decade":
+		groupKey = func(index int, item *spotify.PlaylistTrackResItem) string {
+			if len(item.Track.Album.ReleaseDate) < 4 {
+				return "Unknown"
+			}
+			return item.Track.Album.ReleaseDate[:3] + "0s"
+		}
+	case "artist":
+		groupKey = func(index int, item *spotify.PlaylistTrackResItem) string {
+			if len(item.Track.Artists) == 0 {
+				return "Unknown"
+			}
+			return item.Track.Artists[0].Name
+		}
+	case "added":
+		groupKey = func(index int, item *spotify.PlaylistTrackResItem) string {
+			if item.AddedAt.IsZero() {
+				return "Unknown"
+			}
+			return strconv.Itoa(item.AddedAt.Year())
+		}
+	case "genre":
+		genres := primaryGenres(items)
+		if genres == nil {
+			fmt.Println("Could not get artist genres")
+			return
+		}
+		groupKey = func(index int, item *spotify.PlaylistTrackResItem) string {
+			if len(item.Track.Artists) == 0 || genres[item.Track.Artists[0].ID] == "" {
+				return "Unknown"
+			}
+			return genres[item.Track.Artists[0].ID]
+		}
+	case "chunks":
+		if chunks > len(items) {
+			fmt.Printf("%s only has %d tracks, it can't be split into %d chunks\n", playlist.Name, len(items), chunks)
+			return
+		}
+		groupKey = func(index int, item *spotify.PlaylistTrackResItem) string {
+			return strconv.Itoa(chunkNumber(index, len(items), chunks))
+		}
+	}
+
+	groups := make([]*splitGroup, 0)
+	groupIndex := make(map[string]*splitGroup)
+	for i, item := range items {
+		key := groupKey(i, item)
+		group, ok := groupIndex[key]
+		if !ok {
+			group = &splitGroup{Name: splitName(template, playlist.Name, rule[0], key)}
+			groupIndex[key] = group
+			groups = append(groups, group)
+		}
+		group.Items = append(group.Items, item)
+	}
+
+	kept := make([]*splitGroup, 0, len(groups))
+	for _, group := range groups {
+		if len(group.Items) >= minTracks {
+			kept = append(kept, group)
+		}
+	}
+	if len(kept) == 0 {
+		fmt.Printf("No groups have at least %d tracks\n", minTracks)
+		return
+	}
+
+	fmt.Printf("Split %s into %d playlists:\n", playlist.Name, len(kept))
+	for _, group := range kept {
+		fmt.Printf("%s (%d tracks)\n", group.Name, len(group.Items))
+	}
+	if skipped := len(groups) - len(kept); skipped > 0 {
+		fmt.Printf("%d groups with fewer than %d tracks will be skipped\n", skipped, minTracks)
+	}
+	fmt.Println("Create these playlists? (y/n)")
+	if !getConfirm() {
+		return
+	}
+
+	for _, group := range kept {
+		uris := make([]string, len(group.Items))
+		for i, item := range group.Items {
+			uris[i] = item.Track.URI
+		}
+		_, err := createPlaylistWithTracks(group.Name, uris)
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+	}
+}
+
+// splitName fills in the name template, {group} and the rule's own placeholder are both replaced
+func splitName(template string, source string, rule string, group string) string {
+	placeholders := map[string]string{
+		"decade": "{decade}",
+		"artist": "{artist}",
+		"added":  "{year}",
+		"genre":  "{genre}",
+		"chunks": "{n}",
+	}
+
+	name := strings.Replace(template, "{source}", source, -1)
+	name = strings.Replace(name, "{group}", group, -1)
+	return strings.Replace(name, placeholders[rule], group, -1)
+}
+
+// chunkNumber returns which chunk (starting at 1) the track at index goes in
+//
+// The tracks are spread evenly so there are always exactly the number of chunks asked for
+func chunkNumber(index int, total int, chunks int) int {
+	return index*chunks/total + 1
+}
+
+// primaryGenres returns the first genre of each primary artist of the items, keyed by artist ID
+func primaryGenres(items []*spotify.PlaylistTrackResItem) map[string]string {
+	ids := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, item := range items {
+		if len(item.Track.Artists) == 0 {
+			continue
+		}
+		id := item.Track.Artists[0].ID
+		if id != "" && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	artists := spotify.GetArtists(ids)
+	if artists == nil {
+		return nil
+	}
+
+	genres := make(map[string]string, len(artists))
+	for id, artist := range artists {
+		if len(artist.Genres) > 0 {
+			genres[id] = artist.Genres[0]
+		}
+	}
+	return genres
+}
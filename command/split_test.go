@@ -0,0 +1,62 @@
+package command
+
+import "testing"
+
+func TestChunkNumber(t *testing.T) {
+	tests := []struct {
+		total     int
+		chunks    int
+		wantSizes []int
+	}{
+		{1, 1, []int{1}},
+		{10, 1, []int{10}},
+		{10, 2, []int{5, 5}},
+		{10, 3, []int{4, 3, 3}},
+		{10, 4, []int{3, 2, 3, 2}},
+		{7, 7, []int{1, 1, 1, 1, 1, 1, 1}},
+		{101, 10, []int{11, 10, 10, 10, 10, 10, 10, 10, 10, 10}},
+	}
+
+	for _, test := range tests {
+		sizes := make([]int, test.chunks)
+		previous := 1
+		for index := 0; index < test.total; index++ {
+			chunk := chunkNumber(index, test.total, test.chunks)
+			if chunk < previous || chunk > test.chunks {
+				t.Errorf("%d tracks in %d chunks: track %d went in chunk %d after chunk %d", test.total, test.chunks, index, chunk, previous)
+				break
+			}
+			previous = chunk
+			sizes[chunk-1]++
+		}
+
+		for i := range sizes {
+			if sizes[i] != test.wantSizes[i] {
+				t.Errorf("%d tracks in %d chunks gave sizes %v, want %v", test.total, test.chunks, sizes, test.wantSizes)
+				break
+			}
+		}
+	}
+}
+
+func TestSplitName(t *testing.T) {
+	tests := []struct {
+		template string
+		rule     string
+		group    string
+		want     string
+	}{
+		{"{source} - {decade}", "decade", "1990s", "Mix - 1990s"},
+		{"{source} ({group})", "artist", "Radiohead", "Mix (Radiohead)"},
+		{"{year} from {source}", "added", "2020", "2020 from Mix"},
+		{"{source} part {n}", "chunks", "2", "Mix part 2"},
+		{"{source} {genre} {genre}", "genre", "jazz", "Mix jazz jazz"},
+		{"{source} {decade}", "artist", "Radiohead", "Mix {decade}"},
+	}
+
+	for _, test := range tests {
+		if got := splitName(test.template, "Mix", test.rule, test.group); got != test.want {
+			t.Errorf("splitName(%q, %q, %q) = %q, want %q", test.template, test.rule, test.group, got, test.want)
+		}
+	}
+}
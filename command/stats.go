@@ -0,0 +1,190 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rocketbang/spotify-controller/history"
+	"github.com/rocketbang/spotify-controller/spotify"
+)
+
+var statsHelp = "Use stats [day|week|month|year|all] to see what you listened to, month is used if none is given\n" +
+	"Use --since=<date> to start from a date instead, e.g. --since=2024-01-01\n" +
+	"Use --top=N to change how many entries each list has and --json to print the report as JSON\n" +
+	"Plays are read from the local history, use record on to start recording them"
+
+// heatmapShades are the characters used for the heatmap from fewest to most plays
+const heatmapShades = " .:-=+*#%@"
+
+func statsCommand(args string) {
+	flags, rest := splitFlags(args)
+
+	top, err := intFlag(flags, "top", 10)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+
+	to := time.Now()
+	var from time.Time
+	period := strings.ToLower(strings.TrimSpace(rest))
+	if since, ok := flags["since"]; ok {
+		from, err = time.ParseInLocation("2006-01-02", since, time.Local)
+		if err != nil {
+			fmt.Println("Could not read --since, use a date like 2024-01-01")
+			return
+		}
+	} else {
+		switch period {
+		case "day":
+			from = to.AddDate(0, 0, -1)
+		case "week":
+			from = to.AddDate(0, 0, -7)
+		case "", "month":
+			from = to.AddDate(0, -1, 0)
+		case "year":
+			from = to.AddDate(-1, 0, 0)
+		case "all":
+		default:
+			fmt.Println(statsHelp)
+			return
+		}
+	}
+
+	plays, err := history.Read()
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+	if len(plays) == 0 {
+		fmt.Println("No plays have been recorded yet, use record on to start recording them")
+		return
+	}
+
+	report := history.Summarise(plays, from, to, top)
+	nameContexts(report.MostSkippedPlaylists)
+
+	if flags["json"] != "" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			fmt.Println(err.Error())
+			return
+		}
+		fmt.Println(string(data))
+		return
+	}
+	printReport(report)
+}
+
+// nameContexts swaps playlist URIs for their names where the playlist is one of the users
+func nameContexts(counts []*history.Count) {
+	if len(counts) == 0 {
+		return
+	}
+
+	names := make(map[string]string)
+	for _, playlist := range spotify.GetPlaylists() {
+		names[playlist.URI] = playlist.Name
+	}
+	for _, count := range counts {
+		if name, ok := names[count.Key]; ok {
+			count.Name = name
+		}
+	}
+}
+
+func printReport(report *history.Report) {
+	from := "the start"
+	if !report.From.IsZero() {
+		from = report.From.Local().Format("2006-01-02 15:04")
+	}
+	fmt.Printf("From %s to %s\n", from, report.To.Local().Format("2006-01-02 15:04"))
+	if report.Plays == 0 {
+		fmt.Println("No plays in this period")
+		return
+	}
+
+	fmt.Printf("%d plays, %s listened, %.0f%% skipped\n", report.Plays, formatListened(report.ListenedMs), 100*float64(report.Skips)/float64(report.Plays))
+	fmt.Printf("Current streak %s, longest streak %s\n", formatDays(report.CurrentStreak), formatDays(report.LongestStreak))
+
+	printCounts("Top tracks", report.TopTracks, false)
+	printCounts("Top artists", report.TopArtists, false)
+	printCounts("Top albums", report.TopAlbums, false)
+	printCounts("Most skipped tracks", report.MostSkippedTracks, true)
+	printCounts("Most skipped playlists", report.MostSkippedPlaylists, true)
+	printHeatmap(report.Heatmap)
+}
+
+func formatDays(days int) string {
+	if days == 1 {
+		return "1 day"
+	}
+	return fmt.Sprintf("%d days", days)
+}
+
+func printCounts(title string, counts []*history.Count, skips bool) {
+	if len(counts) == 0 {
+		return
+	}
+
+	fmt.Printf("\n%s:\n", title)
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for i, count := range counts {
+		name := count.Name
+		if count.Artist != "" {
+			name += " - " + count.Artist
+		}
+		if skips {
+			fmt.Fprintf(writer, "%d.\t%s\t%d of %d plays skipped\t%.0f%%\n", i+1, name, count.Skips, count.Plays, 100*count.SkipRate)
+		} else {
+			fmt.Fprintf(writer, "%d.\t%s\t%d plays\t%s\n", i+1, name, count.Plays, formatListened(count.ListenedMs))
+		}
+	}
+	writer.Flush()
+}
+
+func printHeatmap(heatmap [7][24]int) {
+	most := 0
+	for _, hours := range heatmap {
+		for _, plays := range hours {
+			if plays > most {
+				most = plays
+			}
+		}
+	}
+	if most == 0 {
+		return
+	}
+
+	fmt.Println("\nPlays by hour:")
+	fmt.Println("     0     6     12    18")
+	// Start the week on Monday
+	for i := 1; i <= 7; i++ {
+		day := time.Weekday(i % 7)
+		row := make([]byte, 24)
+		for hour, plays := range heatmap[day] {
+			shade := 0
+			if plays == most {
+				shade = len(heatmapShades) - 1
+			} else if plays > 0 {
+				shade = 1 + (plays-1)*(len(heatmapShades)-2)/most
+			}
+			row[hour] = heatmapShades[shade]
+		}
+		fmt.Printf("%s  %s\n", day.String()[:3], string(row))
+	}
+}
+
+func formatListened(listenedMs int64) string {
+	listened := time.Duration(listenedMs) * time.Millisecond
+	hours := int(listened.Hours())
+	minutes := int(listened.Minutes()) % 60
+	if hours > 0 {
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	}
+	return fmt.Sprintf("%dm", minutes)
+}
@@ -0,0 +1,94 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rocketbang/spotify-controller/spotify"
+)
+
+var topHelp = "Use top tracks [short|medium|long] or top artists [short|medium|long]\n" +
+	"short is about the last 4 weeks, medium the last 6 months and long several years, medium is used if none is given\n" +
+	"Use top save [short|medium|long] to save your top tracks to a new playlist, add --name=<name> to name it"
+
+// topRangeNames describe each time range in playlist names
+var topRangeNames = map[string]string{
+	"short":  "last 4 weeks",
+	"medium": "last 6 months",
+	"long":   "all time",
+}
+
+func topCommand(args string) {
+	flags, rest := splitFlags(args)
+	fields := strings.Fields(strings.ToLower(rest))
+	if len(fields) == 0 || len(fields) > 2 {
+		fmt.Println(topHelp)
+		return
+	}
+
+	timeRange := "medium"
+	if len(fields) == 2 {
+		timeRange = fields[1]
+	}
+	if topRangeNames[timeRange] == "" {
+		fmt.Println(topHelp)
+		return
+	}
+
+	switch fields[0] {
+	case "tracks":
+		tracks := spotify.GetTopTracks(timeRange)
+		if tracks == nil {
+			fmt.Println("Could not get top tracks")
+			return
+		}
+		fmt.Printf("Top tracks, %s:\n", topRangeNames[timeRange])
+		for i, track := range tracks {
+			artist := ""
+			if len(track.Artists) > 0 {
+				artist = " - " + track.Artists[0].Name
+			}
+			fmt.Printf("%d. %s%s\n", i+1, track.Name, artist)
+		}
+	case "artists":
+		artists := spotify.GetTopArtists(timeRange)
+		if artists == nil {
+			fmt.Println("Could not get top artists")
+			return
+		}
+		fmt.Printf("Top artists, %s:\n", topRangeNames[timeRange])
+		for i, artist := range artists {
+			fmt.Printf("%d. %s\n", i+1, artist.Name)
+		}
+	case "save":
+		saveTopTracks(timeRange, flags["name"])
+	default:
+		fmt.Println(topHelp)
+	}
+}
+
+func saveTopTracks(timeRange string, playlistName string) {
+	tracks := spotify.GetTopTracks(timeRange)
+	if tracks == nil {
+		fmt.Println("Could not get top tracks")
+		return
+	}
+	if len(tracks) == 0 {
+		fmt.Println("Spotify doesn't have any top tracks for you yet")
+		return
+	}
+
+	if playlistName == "" || playlistName == "true" {
+		playlistName = fmt.Sprintf("Top tracks (%s) %s", topRangeNames[timeRange], time.Now().Format("2006-01-02"))
+	}
+
+	uris := make([]string, len(tracks))
+	for i, track := range tracks {
+		uris[i] = track.URI
+	}
+	_, err := createPlaylistWithTracks(playlistName, uris)
+	if err != nil {
+		fmt.Println(err.Error())
+	}
+}
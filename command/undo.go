@@ -0,0 +1,60 @@
+package command
+
+import (
+	"fmt"
+
+	"github.com/rocketbang/spotify-controller/spotify"
+)
+
+func undo() {
+	next := spotify.NextUndo()
+	if next == nil {
+		fmt.Println("Nothing to undo")
+		return
+	}
+	if !backupBeforeChange(next.PlaylistID, "undo") {
+		return
+	}
+
+	entry, err := spotify.Undo()
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+	fmt.Printf("Undid %s\n", describeChange(entry))
+}
+
+func redo() {
+	next := spotify.NextRedo()
+	if next == nil {
+		fmt.Println("Nothing to redo")
+		return
+	}
+	if !backupBeforeChange(next.PlaylistID, "redo") {
+		return
+	}
+
+	entry, err := spotify.Redo()
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+	fmt.Printf("Redid %s\n", describeChange(entry))
+}
+
+func describeChange(entry *spotify.JournalEntry) string {
+	tracks := fmt.Sprintf("%d tracks", len(entry.URIs))
+	if len(entry.URIs) == 1 {
+		tracks = "1 track"
+	}
+
+	name := entry.PlaylistID
+	if playlist := spotify.GetPlaylist(entry.PlaylistID); playlist != nil {
+		name = playlist.Name
+	}
+
+	if entry.Action == "remove" {
+		return fmt.Sprintf("removing %s from %s", tracks, name)
+	}
+	return fmt.Sprintf("adding %s to %s", tracks, name)
+}
@@ -13,6 +13,27 @@ type Config struct {
 	ClientID     string `json:"clientId"`
 	ClientSecret string `json:"clientSecret"`
 	Debug        bool   `json:"debug"`
+	// DataDir is where local data such as playlist backups is kept
+	DataDir        string          `json:"dataDir"`
+	SmartPlaylists []SmartPlaylist `json:"smartPlaylists"`
+	// FavouritePlaylists are the names or IDs of the playlists add --favourites adds to
+	FavouritePlaylists []string `json:"favouritePlaylists"`
+	// RecordHistory starts the listening history recorder when the app starts
+	RecordHistory bool `json:"recordHistory"`
+}
+
+// SmartPlaylist is a playlist built from rules that the smart sync command keeps up to date
+type SmartPlaylist struct {
+	Name string `json:"name"`
+	// Sources are where tracks come from: "playlist:<name>", "liked", "top[:short|medium|long]" or "recent"
+	Sources []string `json:"sources"`
+	// Filters are rules such as "year >= 2000", "artist = Radiohead" or "added within 30" that every track must match
+	Filters []string `json:"filters"`
+	// Order uses the same fields as the sort command, e.g. "added desc", or "shuffle"
+	Order string `json:"order"`
+	Limit int    `json:"limit"`
+	// Cover gives the playlist a cover made from its album art each time it's synced
+	Cover bool `json:"cover"`
 }
 
 // Load will load the current config
@@ -45,5 +66,26 @@ func Load() error {
 	return nil
 }
 
+// DataPath will return the path to the given folder inside the data directory, creating it if needed
+//
+// The data directory defaults to spotify-controller inside the user config directory
+func DataPath(folder string) (string, error) {
+	dataDir := Value.DataDir
+	if dataDir == "" {
+		configDir, err := os.UserConfigDir()
+		if err != nil {
+			return "", err
+		}
+		dataDir = filepath.Join(configDir, "spotify-controller")
+	}
+
+	path := filepath.Join(dataDir, folder)
+	err := os.MkdirAll(path, 0700)
+	if err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
 // Value is the current config value
 var Value Config
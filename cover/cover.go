@@ -0,0 +1,127 @@
+package cover
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"os"
+
+	// Registers PNG so image.Decode can read it
+	_ "image/png"
+)
+
+// MaxSide is the width and height covers are scaled down to, the largest size spotify shows them at
+const MaxSide = 640
+
+// minSide is the smallest Fit will shrink an image to
+const minSide = 64
+
+// Load reads a PNG or JPEG image file
+func Load(path string) (image.Image, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return nil, errors.New("Could not read " + path + ", only PNG and JPEG images are supported")
+	}
+	return img, nil
+}
+
+// Fit crops the image to a square and encodes it as a JPEG that is at most maxSize bytes once base64 encoded
+//
+// The image is tried at its own size, up to MaxSide, first. The quality is lowered, then the image is made
+// smaller until it fits, down to minSide
+func Fit(img image.Image, maxSize int) ([]byte, error) {
+	side := img.Bounds().Dx()
+	if img.Bounds().Dy() < side {
+		side = img.Bounds().Dy()
+	}
+	if side == 0 {
+		return nil, errors.New("The image is empty")
+	}
+	if side > MaxSide {
+		side = MaxSide
+	}
+
+	square := cropSquare(img)
+	for {
+		scaled := Resize(square, side, side)
+		for quality := 90; quality >= 40; quality -= 10 {
+			buf := new(bytes.Buffer)
+			err := jpeg.Encode(buf, scaled, &jpeg.Options{Quality: quality})
+			if err != nil {
+				return nil, err
+			}
+			if base64.StdEncoding.EncodedLen(buf.Len()) <= maxSize {
+				return buf.Bytes(), nil
+			}
+		}
+		if side*3/4 < minSide {
+			break
+		}
+		side = side * 3 / 4
+	}
+	return nil, errors.New("Could not make the image small enough")
+}
+
+// cropSquare returns the largest square in the centre of the image
+func cropSquare(img image.Image) image.Image {
+	bounds := img.Bounds()
+	side := bounds.Dx()
+	if bounds.Dy() < side {
+		side = bounds.Dy()
+	}
+
+	x := bounds.Min.X + (bounds.Dx()-side)/2
+	y := bounds.Min.Y + (bounds.Dy()-side)/2
+	square := image.NewRGBA(image.Rect(0, 0, side, side))
+	draw.Draw(square, square.Bounds(), img, image.Point{x, y}, draw.Src)
+	return square
+}
+
+// Resize scales the image to the given size, averaging the pixels that make up each new pixel
+func Resize(img image.Image, width int, height int) *image.RGBA {
+	bounds := img.Bounds()
+	resized := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	for y := 0; y < height; y++ {
+		startY := bounds.Min.Y + y*bounds.Dy()/height
+		endY := bounds.Min.Y + (y+1)*bounds.Dy()/height
+		if endY == startY {
+			endY++
+		}
+		for x := 0; x < width; x++ {
+			startX := bounds.Min.X + x*bounds.Dx()/width
+			endX := bounds.Min.X + (x+1)*bounds.Dx()/width
+			if endX == startX {
+				endX++
+			}
+
+			var r, g, b, a, count uint64
+			for sy := startY; sy < endY; sy++ {
+				for sx := startX; sx < endX; sx++ {
+					pr, pg, pb, pa := img.At(sx, sy).RGBA()
+					r += uint64(pr)
+					g += uint64(pg)
+					b += uint64(pb)
+					a += uint64(pa)
+					count++
+				}
+			}
+
+			offset := resized.PixOffset(x, y)
+			resized.Pix[offset] = uint8((r / count) >> 8)
+			resized.Pix[offset+1] = uint8((g / count) >> 8)
+			resized.Pix[offset+2] = uint8((b / count) >> 8)
+			resized.Pix[offset+3] = uint8((a / count) >> 8)
+		}
+	}
+	return resized
+}
@@ -0,0 +1,92 @@
+package cover
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"net/http"
+
+	// Registers JPEG so image.Decode can read album art
+	_ "image/jpeg"
+)
+
+// Fetch downloads and decodes the image at the URL
+func Fetch(client *http.Client, url string) (image.Image, error) {
+	res, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return nil, fmt.Errorf("Could not get image %s, status %d", url, res.StatusCode)
+	}
+
+	img, _, err := image.Decode(res.Body)
+	if err != nil {
+		return nil, errors.New("Could not read image " + url)
+	}
+	return img, nil
+}
+
+// Mosaic lays the tiles out in a square grid with the name written across the bottom
+//
+// A 3x3 grid is used with 9 or more tiles, 2x2 with 4 or more and otherwise only the first tile
+func Mosaic(tiles []image.Image, name string) image.Image {
+	mosaic := image.NewRGBA(image.Rect(0, 0, MaxSide, MaxSide))
+	draw.Draw(mosaic, mosaic.Bounds(), image.NewUniform(color.RGBA{30, 30, 30, 255}), image.Point{}, draw.Src)
+
+	grid := 1
+	if len(tiles) >= 9 {
+		grid = 3
+	} else if len(tiles) >= 4 {
+		grid = 2
+	}
+
+	if len(tiles) > 0 {
+		tileSide := MaxSide / grid
+		for i := 0; i < grid*grid; i++ {
+			tile := Resize(cropSquare(tiles[i]), tileSide, tileSide)
+			at := image.Pt((i%grid)*tileSide, (i/grid)*tileSide)
+			draw.Draw(mosaic, image.Rectangle{at, at.Add(image.Pt(tileSide, tileSide))}, tile, image.Point{}, draw.Src)
+		}
+	}
+
+	drawName(mosaic, name)
+	return mosaic
+}
+
+// drawName writes the name on a dark band at the bottom of the image, using the largest text that fits in three lines
+func drawName(img *image.RGBA, name string) {
+	text := fontText(name)
+	if len(text) == 0 {
+		return
+	}
+
+	side := img.Bounds().Dx()
+	padding := side / 32
+	var lines []string
+	scale := 8
+	for ; scale > 2; scale-- {
+		lines = wrapText(text, (side-padding*2)/((glyphWidth+1)*scale))
+		if len(lines) <= 3 {
+			break
+		}
+	}
+	if len(lines) > 3 {
+		lines = lines[:3]
+	}
+
+	lineHeight := (glyphHeight + 3) * scale
+	bandTop := img.Bounds().Dy() - len(lines)*lineHeight - padding*2
+	band := image.Rect(0, bandTop, side, img.Bounds().Dy())
+	draw.Draw(img, band, image.NewUniform(color.RGBA{0, 0, 0, 170}), image.Point{}, draw.Over)
+
+	for i, line := range lines {
+		width := (len(line)*(glyphWidth+1) - 1) * scale
+		at := image.Pt((side-width)/2, bandTop+padding+i*lineHeight+scale)
+		drawText(img, line, at, scale, color.White)
+	}
+}
@@ -0,0 +1,142 @@
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/rocketbang/spotify-controller/config"
+)
+
+// Play is a single play of a track recorded by the history recorder
+type Play struct {
+	TrackURI   string    `json:"trackUri"`
+	TrackName  string    `json:"trackName"`
+	ArtistID   string    `json:"artistId"`
+	ArtistName string    `json:"artistName"`
+	AlbumName  string    `json:"albumName"`
+	ContextURI string    `json:"contextUri"`
+	Device     string    `json:"device"`
+	StartedAt  time.Time `json:"startedAt"`
+	DurationMs int       `json:"durationMs"`
+	// ListenedMs is how long the track was actually playing for, pauses aren't counted
+	ListenedMs int `json:"listenedMs"`
+	// Skipped is true if another track replaced it before the end was reached, stopping playback isn't a skip
+	Skipped bool `json:"skipped"`
+}
+
+func historyFile(name string) (string, error) {
+	dir, err := config.DataPath("history")
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name), nil
+}
+
+// Append will add the play to the end of the stored history
+func Append(play *Play) error {
+	path, err := historyFile("plays.jsonl")
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(play)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(data, '\n'))
+	return err
+}
+
+// Read will return every stored play, oldest first
+//
+// Lines that can't be decoded, such as one cut short by a crash, are skipped
+func Read() ([]*Play, error) {
+	path, err := historyFile("plays.jsonl")
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return []*Play{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	plays := make([]*Play, 0)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		play := &Play{}
+		if json.Unmarshal(scanner.Bytes(), play) == nil {
+			plays = append(plays, play)
+		}
+	}
+	return plays, scanner.Err()
+}
+
+// inProgress is the play the recorder is following and how far through the track it was at the last poll
+type inProgress struct {
+	Play           *Play `json:"play"`
+	LastProgressMs int   `json:"lastProgressMs"`
+}
+
+// saveCurrent stores the play in progress so it can be picked up again after a restart, nil clears it
+func saveCurrent(play *Play, lastProgressMs int) error {
+	path, err := historyFile("current.json")
+	if err != nil {
+		return err
+	}
+
+	if play == nil {
+		err = os.Remove(path)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	data, err := json.Marshal(&inProgress{Play: play, LastProgressMs: lastProgressMs})
+	if err != nil {
+		return err
+	}
+
+	tmpPath := path + ".tmp"
+	err = ioutil.WriteFile(tmpPath, data, 0600)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// loadCurrent returns the play that was in progress when the recorder last stopped, if any
+func loadCurrent() (*Play, int) {
+	path, err := historyFile("current.json")
+	if err != nil {
+		return nil, 0
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, 0
+	}
+
+	current := &inProgress{}
+	if json.Unmarshal(data, current) != nil {
+		return nil, 0
+	}
+	return current.Play, current.LastProgressMs
+}
@@ -0,0 +1,257 @@
+package history
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rocketbang/spotify-controller/spotify"
+)
+
+// PollInterval is how often the recorder checks what is playing
+const PollInterval = 10 * time.Second
+
+// endMargin is how close to the end a track has to get to not count as skipped, allowing for the
+// last part of the track being played between polls
+const endMargin = PollInterval + 5*time.Second
+
+// minSkipRemaining is how much of the track has to be left for skipping with next to count as a skip
+const minSkipRemaining = 10 * time.Second
+
+// maxPollBackoff is the longest the recorder waits between polls while spotify can't be reached
+const maxPollBackoff = 5 * time.Minute
+
+var recorderMutex sync.Mutex
+var stopRecorder chan struct{}
+var activeRecorder *recorder
+
+// StartRecording will start recording plays in the background, it does nothing if already recording
+func StartRecording() {
+	recorderMutex.Lock()
+	defer recorderMutex.Unlock()
+
+	if stopRecorder != nil {
+		return
+	}
+	stop := make(chan struct{})
+	stopRecorder = stop
+	activeRecorder = newRecorder()
+	go activeRecorder.run(stop)
+}
+
+// StopRecording will stop recording plays, the play in progress is kept and carried on with next time
+func StopRecording() {
+	recorderMutex.Lock()
+	defer recorderMutex.Unlock()
+
+	if stopRecorder != nil {
+		close(stopRecorder)
+		stopRecorder = nil
+		activeRecorder = nil
+	}
+}
+
+// PauseRecording stops recording while the app itself is changing playback, such as when analysing shuffle
+//
+// The play in progress is finished without counting as a skip. Call the returned function to carry on recording,
+// it does nothing if recording wasn't on
+func PauseRecording() func() {
+	recorderMutex.Lock()
+	r := activeRecorder
+	recorderMutex.Unlock()
+	if r == nil {
+		return func() {}
+	}
+
+	StopRecording()
+	r.mutex.Lock()
+	if r.current != nil {
+		r.finish(false)
+	}
+	r.mutex.Unlock()
+	return StartRecording
+}
+
+// RecordSkip will finish the current play as skipped, call it just before skipping to the next track
+//
+// The recorder would notice the skip on its next poll, but by then it can't tell how far through the track was.
+// It isn't counted as a skip if the track was almost over
+func RecordSkip() {
+	recordTrackChange(true)
+}
+
+// RecordPrevious will finish the current play without counting it as a skip, call it just before going back a track
+func RecordPrevious() {
+	recordTrackChange(false)
+}
+
+func recordTrackChange(skip bool) {
+	recorderMutex.Lock()
+	r := activeRecorder
+	recorderMutex.Unlock()
+	if r == nil {
+		return
+	}
+
+	state, err := spotify.GetPlaybackState()
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if err == nil {
+		r.poll(state, time.Now())
+	}
+	if r.current != nil {
+		remaining := r.current.DurationMs - r.lastProgress
+		r.finish(skip && remaining > int(minSkipRemaining/time.Millisecond))
+	}
+}
+
+// Recording returns true if plays are being recorded
+func Recording() bool {
+	recorderMutex.Lock()
+	defer recorderMutex.Unlock()
+
+	return stopRecorder != nil
+}
+
+// recorder follows playback from one poll to the next
+type recorder struct {
+	mutex        sync.Mutex
+	current      *Play
+	lastProgress int
+	lastPoll     time.Time
+}
+
+func newRecorder() *recorder {
+	r := &recorder{}
+	r.current, r.lastProgress = loadCurrent()
+
+	// A play left over from a long time ago can't still be going, so it's finished straight away
+	if r.current != nil {
+		end := r.current.StartedAt.Add(time.Duration(r.current.DurationMs)*time.Millisecond + endMargin)
+		if time.Now().After(end) {
+			r.finish(false)
+		}
+	}
+	return r
+}
+
+func (r *recorder) run(stop <-chan struct{}) {
+	wait := PollInterval
+	for {
+		state, err := spotify.GetPlaybackState()
+		r.mutex.Lock()
+		// Don't poll once stopped, the play in progress may have been finished while the state was fetched
+		select {
+		case <-stop:
+			r.mutex.Unlock()
+			return
+		default:
+		}
+		// Keep the current play going if spotify couldn't be reached
+		if err == nil {
+			r.poll(state, time.Now())
+		}
+		r.mutex.Unlock()
+
+		// Back off while spotify can't be reached, only saying so when it starts and stops
+		if err != nil {
+			if wait == PollInterval {
+				fmt.Printf("Recorder can't reach spotify, will keep trying: %s\n", err.Error())
+			}
+			wait *= 2
+			if wait > maxPollBackoff {
+				wait = maxPollBackoff
+			}
+		} else if wait != PollInterval {
+			fmt.Println("Recorder reached spotify again")
+			wait = PollInterval
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+func (r *recorder) poll(state *spotify.PlaybackState, now time.Time) {
+	elapsed := int(now.Sub(r.lastPoll) / time.Millisecond)
+	r.lastPoll = now
+
+	// Stopping playback isn't a skip, even part way through the track
+	if state.TrackURI == "" {
+		if r.current != nil {
+			r.finish(false)
+		}
+		return
+	}
+
+	if r.current != nil && r.current.TrackURI == state.TrackURI {
+		// Jumping back from the end of the track means it's being played again
+		replayed := state.ProgressMs < r.lastProgress && r.endReached()
+		if !replayed {
+			if state.IsPlaying && state.ProgressMs > r.lastProgress {
+				listened := state.ProgressMs - r.lastProgress
+				if listened > elapsed {
+					listened = elapsed
+				}
+				r.current.ListenedMs += listened
+			}
+			r.lastProgress = state.ProgressMs
+			r.save()
+			return
+		}
+	}
+
+	// Another track replacing this one before the end means it was skipped
+	if r.current != nil {
+		r.finish(r.current.TrackURI != state.TrackURI && !r.endReached())
+	}
+
+	r.current = &Play{
+		TrackURI:   state.TrackURI,
+		TrackName:  state.TrackName,
+		ArtistID:   state.ArtistID,
+		ArtistName: state.ArtistName,
+		AlbumName:  state.AlbumName,
+		ContextURI: state.ContextURI,
+		Device:     state.DeviceName,
+		StartedAt:  now.Add(-time.Duration(state.ProgressMs) * time.Millisecond),
+		DurationMs: state.DurationMs,
+		ListenedMs: state.ProgressMs,
+	}
+	r.lastProgress = state.ProgressMs
+	r.save()
+}
+
+// endReached returns true if the current play got close enough to the end of the track to have finished
+func (r *recorder) endReached() bool {
+	return r.lastProgress >= r.current.DurationMs-int(endMargin/time.Millisecond)
+}
+
+// finish stores the current play
+func (r *recorder) finish(skipped bool) {
+	play := r.current
+	r.current = nil
+	r.lastProgress = 0
+
+	if play.ListenedMs > play.DurationMs && play.DurationMs > 0 {
+		play.ListenedMs = play.DurationMs
+	}
+	play.Skipped = skipped
+
+	err := Append(play)
+	if err != nil {
+		fmt.Printf("Could not record play of %s: %s\n", play.TrackName, err.Error())
+	}
+	r.save()
+}
+
+func (r *recorder) save() {
+	err := saveCurrent(r.current, r.lastProgress)
+	if err != nil {
+		fmt.Printf("Could not save the play in progress: %s\n", err.Error())
+	}
+}
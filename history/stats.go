@@ -0,0 +1,207 @@
+package history
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// minSkipPlays is how many times a track or playlist has to be played before its skip rate is reported
+const minSkipPlays = 3
+
+// Count is how much a track, artist, album or playlist was listened to
+type Count struct {
+	// Key is the track or context URI, or the artist or album name
+	Key        string  `json:"key"`
+	Name       string  `json:"name"`
+	Artist     string  `json:"artist,omitempty"`
+	Plays      int     `json:"plays"`
+	Skips      int     `json:"skips"`
+	SkipRate   float64 `json:"skipRate"`
+	ListenedMs int64   `json:"listenedMs"`
+}
+
+// Report is a summary of the plays in a period
+type Report struct {
+	From       time.Time `json:"from"`
+	To         time.Time `json:"to"`
+	Plays      int       `json:"plays"`
+	Skips      int       `json:"skips"`
+	ListenedMs int64     `json:"listenedMs"`
+	TopTracks  []*Count  `json:"topTracks"`
+	TopArtists []*Count  `json:"topArtists"`
+	TopAlbums  []*Count  `json:"topAlbums"`
+	// MostSkippedTracks and MostSkippedPlaylists only include those played at least minSkipPlays times
+	MostSkippedTracks    []*Count `json:"mostSkippedTracks"`
+	MostSkippedPlaylists []*Count `json:"mostSkippedPlaylists"`
+	// Heatmap is the number of plays in each hour of each weekday in local time, Sunday first
+	Heatmap [7][24]int `json:"heatmap"`
+	// CurrentStreak is how many days in a row up to today or yesterday had a play, counting plays from before the period
+	// LongestStreak is the most days in a row with a play within the period
+	CurrentStreak int `json:"currentStreak"`
+	LongestStreak int `json:"longestStreak"`
+}
+
+// Summarise builds a report of the plays started between from and to, with up to top entries in each list
+func Summarise(plays []*Play, from time.Time, to time.Time, top int) *Report {
+	report := &Report{From: from, To: to}
+
+	tracks := newCounter()
+	artists := newCounter()
+	albums := newCounter()
+	playlists := newCounter()
+	days := make(map[string]bool)
+	allDays := make(map[string]bool)
+
+	for _, play := range plays {
+		allDays[play.StartedAt.Local().Format("2006-01-02")] = true
+		if play.StartedAt.Before(from) || play.StartedAt.After(to) {
+			continue
+		}
+
+		report.Plays++
+		report.ListenedMs += int64(play.ListenedMs)
+		if play.Skipped {
+			report.Skips++
+		}
+
+		tracks.add(play.TrackURI, play.TrackName, play.ArtistName, play)
+		if play.ArtistName != "" {
+			artists.add(play.ArtistName, play.ArtistName, "", play)
+		}
+		if play.AlbumName != "" {
+			albums.add(play.AlbumName+"\n"+play.ArtistName, play.AlbumName, play.ArtistName, play)
+		}
+		if strings.HasPrefix(play.ContextURI, "spotify:playlist:") {
+			playlists.add(play.ContextURI, play.ContextURI, "", play)
+		}
+
+		local := play.StartedAt.Local()
+		report.Heatmap[local.Weekday()][local.Hour()]++
+		days[local.Format("2006-01-02")] = true
+	}
+
+	report.TopTracks = tracks.top(top, byPlays)
+	report.TopArtists = artists.top(top, byPlays)
+	report.TopAlbums = albums.top(top, byPlays)
+	report.MostSkippedTracks = tracks.skipped().top(top, bySkipRate)
+	report.MostSkippedPlaylists = playlists.skipped().top(top, bySkipRate)
+	report.CurrentStreak, _ = streaks(allDays)
+	_, report.LongestStreak = streaks(days)
+	return report
+}
+
+// QuickSkips counts how many times each track was skipped before it had played for the given time, keyed by track URI
+//
+// Only plays from the given context, such as a playlist URI, are counted, leave it empty to count every play
+func QuickSkips(plays []*Play, contextURI string, within time.Duration) map[string]int {
+	skips := make(map[string]int)
+	for _, play := range plays {
+		if contextURI != "" && play.ContextURI != contextURI {
+			continue
+		}
+		if play.Skipped && time.Duration(play.ListenedMs)*time.Millisecond <= within {
+			skips[play.TrackURI]++
+		}
+	}
+	return skips
+}
+
+// counter totals plays by key, keeping the order keys were first seen so ties are stable
+type counter struct {
+	counts map[string]*Count
+	order  []*Count
+}
+
+func newCounter() *counter {
+	return &counter{counts: make(map[string]*Count)}
+}
+
+func (c *counter) add(key string, name string, artist string, play *Play) {
+	count, ok := c.counts[key]
+	if !ok {
+		count = &Count{Key: key, Name: name, Artist: artist}
+		c.counts[key] = count
+		c.order = append(c.order, count)
+	}
+
+	count.Plays++
+	count.ListenedMs += int64(play.ListenedMs)
+	if play.Skipped {
+		count.Skips++
+	}
+	count.SkipRate = float64(count.Skips) / float64(count.Plays)
+}
+
+// skipped returns a counter with only the entries that were played often enough to have a skip rate
+func (c *counter) skipped() *counter {
+	result := newCounter()
+	for _, count := range c.order {
+		if count.Plays >= minSkipPlays && count.Skips > 0 {
+			result.counts[count.Key] = count
+			result.order = append(result.order, count)
+		}
+	}
+	return result
+}
+
+func byPlays(a *Count, b *Count) bool {
+	if a.Plays != b.Plays {
+		return a.Plays > b.Plays
+	}
+	return a.ListenedMs > b.ListenedMs
+}
+
+func bySkipRate(a *Count, b *Count) bool {
+	if a.SkipRate != b.SkipRate {
+		return a.SkipRate > b.SkipRate
+	}
+	return a.Plays > b.Plays
+}
+
+func (c *counter) top(n int, less func(a *Count, b *Count) bool) []*Count {
+	sorted := append([]*Count{}, c.order...)
+	sort.SliceStable(sorted, func(i, j int) bool { return less(sorted[i], sorted[j]) })
+	if n > 0 && len(sorted) > n {
+		sorted = sorted[:n]
+	}
+	return sorted
+}
+
+// streaks returns the current and longest runs of consecutive days that have a play
+func streaks(days map[string]bool) (int, int) {
+	if len(days) == 0 {
+		return 0, 0
+	}
+
+	dates := make([]time.Time, 0, len(days))
+	for day := range days {
+		date, err := time.ParseInLocation("2006-01-02", day, time.Local)
+		if err == nil {
+			dates = append(dates, date)
+		}
+	}
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+
+	longest := 1
+	run := 1
+	for i := 1; i < len(dates); i++ {
+		// AddDate rather than adding 24 hours so daylight saving changes don't break a run
+		if dates[i-1].AddDate(0, 0, 1).Equal(dates[i]) {
+			run++
+		} else {
+			run = 1
+		}
+		if run > longest {
+			longest = run
+		}
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	last := dates[len(dates)-1]
+	if !last.Equal(today) && !last.AddDate(0, 0, 1).Equal(today) {
+		return 0, longest
+	}
+	return run, longest
+}
@@ -0,0 +1,135 @@
+package history
+
+import (
+	"testing"
+	"time"
+)
+
+// daysAgo returns noon on the day the given number of days before today in local time
+func daysAgo(days int) time.Time {
+	now := time.Now()
+	return time.Date(now.Year(), now.Month(), now.Day()-days, 12, 0, 0, 0, time.Local)
+}
+
+func dayKeys(offsets ...int) map[string]bool {
+	days := make(map[string]bool)
+	for _, offset := range offsets {
+		days[daysAgo(offset).Format("2006-01-02")] = true
+	}
+	return days
+}
+
+func TestStreaks(t *testing.T) {
+	tests := []struct {
+		name        string
+		days        map[string]bool
+		wantCurrent int
+		wantLongest int
+	}{
+		{"no plays", dayKeys(), 0, 0},
+		{"today", dayKeys(0), 1, 1},
+		{"yesterday", dayKeys(1), 1, 1},
+		{"two days ago", dayKeys(2), 0, 1},
+		{"today and yesterday", dayKeys(0, 1), 2, 2},
+		{"gap yesterday", dayKeys(0, 2, 3), 1, 2},
+		{"longer run in the past", dayKeys(0, 1, 5, 6, 7, 8, 9), 2, 5},
+		{"broken run", dayKeys(3, 4, 5, 6), 0, 4},
+		{"month boundary", dayKeys(0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 21, 22, 23, 24, 25, 26, 27, 28, 29, 30, 31, 32), 33, 33},
+	}
+
+	for _, test := range tests {
+		current, longest := streaks(test.days)
+		if current != test.wantCurrent || longest != test.wantLongest {
+			t.Errorf("%s: got current %d and longest %d, want %d and %d", test.name, current, longest, test.wantCurrent, test.wantLongest)
+		}
+	}
+}
+
+func TestSummariseStreaks(t *testing.T) {
+	from := daysAgo(2).Add(-12 * time.Hour)
+	to := daysAgo(-1)
+
+	tests := []struct {
+		name        string
+		offsets     []int
+		wantPlays   int
+		wantCurrent int
+		wantLongest int
+	}{
+		{"only in the period", []int{0, 1, 2}, 3, 3, 3},
+		{"current streak counts plays before the period", []int{0, 1, 2, 3, 4, 5}, 3, 6, 3},
+		{"longest streak only counts the period", []int{1, 6, 7, 8, 9}, 1, 1, 1},
+		{"nothing in the period", []int{5, 6}, 0, 0, 0},
+	}
+
+	for _, test := range tests {
+		plays := make([]*Play, 0)
+		for _, offset := range test.offsets {
+			plays = append(plays, &Play{TrackURI: "spotify:track:1", StartedAt: daysAgo(offset)})
+		}
+
+		report := Summarise(plays, from, to, 5)
+		if report.Plays != test.wantPlays || report.CurrentStreak != test.wantCurrent || report.LongestStreak != test.wantLongest {
+			t.Errorf("%s: got %d plays, current %d and longest %d, want %d, %d and %d", test.name,
+				report.Plays, report.CurrentStreak, report.LongestStreak, test.wantPlays, test.wantCurrent, test.wantLongest)
+		}
+	}
+}
+
+func TestSummarise(t *testing.T) {
+	start := time.Date(2020, 3, 2, 9, 0, 0, 0, time.Local)
+	at := func(hours int) time.Time { return start.Add(time.Duration(hours) * time.Hour) }
+	playlist := "spotify:playlist:road"
+
+	plays := []*Play{
+		{TrackURI: "a", TrackName: "A", ArtistName: "X", AlbumName: "One", ContextURI: playlist, StartedAt: at(0), ListenedMs: 1000},
+		{TrackURI: "a", TrackName: "A", ArtistName: "X", AlbumName: "One", ContextURI: playlist, StartedAt: at(1), ListenedMs: 1000, Skipped: true},
+		{TrackURI: "a", TrackName: "A", ArtistName: "X", AlbumName: "One", ContextURI: playlist, StartedAt: at(2), ListenedMs: 1000},
+		{TrackURI: "b", TrackName: "B", ArtistName: "Y", AlbumName: "Two", ContextURI: "spotify:album:two", StartedAt: at(3), ListenedMs: 5000, Skipped: true},
+		{TrackURI: "b", TrackName: "B", ArtistName: "Y", AlbumName: "Two", StartedAt: at(4), ListenedMs: 5000, Skipped: true},
+		{TrackURI: "b", TrackName: "B", ArtistName: "Y", AlbumName: "Two", StartedAt: at(5), ListenedMs: 5000, Skipped: true},
+		{TrackURI: "c", TrackName: "C", ArtistName: "X", AlbumName: "Three", StartedAt: at(6), ListenedMs: 500},
+		// Outside the period
+		{TrackURI: "c", TrackName: "C", ArtistName: "X", AlbumName: "Three", StartedAt: at(-48)},
+		{TrackURI: "c", TrackName: "C", ArtistName: "X", AlbumName: "Three", StartedAt: at(48)},
+	}
+
+	report := Summarise(plays, at(-1), at(24), 2)
+
+	if report.Plays != 7 || report.Skips != 4 || report.ListenedMs != 18500 {
+		t.Errorf("got %d plays, %d skips and %dms, want 7, 4 and 18500ms", report.Plays, report.Skips, report.ListenedMs)
+	}
+
+	tests := []struct {
+		name  string
+		got   []*Count
+		keys  []string
+		plays []int
+	}{
+		// a and b tie on plays, b listened for longer
+		{"top tracks", report.TopTracks, []string{"b", "a"}, []int{3, 3}},
+		{"top artists", report.TopArtists, []string{"X", "Y"}, []int{4, 3}},
+		{"top albums", report.TopAlbums, []string{"Two\nY", "One\nX"}, []int{3, 3}},
+		{"most skipped tracks", report.MostSkippedTracks, []string{"b", "a"}, []int{3, 3}},
+		{"most skipped playlists", report.MostSkippedPlaylists, []string{playlist}, []int{3}},
+	}
+
+	for _, test := range tests {
+		if len(test.got) != len(test.keys) {
+			t.Errorf("%s: got %d entries, want %d", test.name, len(test.got), len(test.keys))
+			continue
+		}
+		for i, count := range test.got {
+			if count.Key != test.keys[i] || count.Plays != test.plays[i] {
+				t.Errorf("%s: entry %d is %q with %d plays, want %q with %d", test.name, i, count.Key, count.Plays, test.keys[i], test.plays[i])
+			}
+		}
+	}
+
+	if rate := report.MostSkippedTracks[1].SkipRate; rate < 0.33 || rate > 0.34 {
+		t.Errorf("skip rate of a = %v, want 1/3", rate)
+	}
+	if count := report.Heatmap[start.Weekday()][9]; count != 1 {
+		t.Errorf("heatmap at 9am = %d, want 1", count)
+	}
+}
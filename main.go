@@ -77,6 +77,7 @@ func main() {
 		"user-read-recently-played",
 		"user-follow-read",
 		"user-follow-modify",
+		"ugc-image-upload",
 	})
 	browser.OpenURL("https://accounts.spotify.com/authorize?client_id=401772871f2b4065822277a15d71e6d2&response_type=code&redirect_uri=http%3A%2F%2Flocalhost%3A8282%2Fcallback&scope=" + scopeString)
 
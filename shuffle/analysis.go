@@ -0,0 +1,273 @@
+package shuffle
+
+import (
+	"math"
+	"sort"
+)
+
+// Report is the result of analysing a set of shuffled rounds
+type Report struct {
+	Rounds int
+	Depth  int
+	// Tracks is the number of tracks in the playlist that was shuffled
+	Tracks int
+
+	// Targets has the position histogram for each target track
+	Targets []*TargetReport
+
+	// Coverage compares how often each track appeared within depth against a uniform shuffle
+	Coverage ChiSquare
+
+	// ArtistRepeatRate is the fraction of neighbouring tracks that share an artist
+	ArtistRepeatRate float64
+	// ExpectedArtistRepeatRate is the artist repeat rate a uniform shuffle would give
+	ExpectedArtistRepeatRate float64
+
+	// RecencyScore is the average added date percentile of the tracks seen (0 oldest, 1 newest)
+	//
+	// A uniform shuffle gives 0.5
+	RecencyScore float64
+}
+
+// TargetReport shows where a single track appeared across the rounds
+type TargetReport struct {
+	Track Track
+	// Positions counts how many rounds the track was at each position
+	Positions []int
+	// Missing is the number of rounds the track did not appear within depth
+	Missing   int
+	ChiSquare ChiSquare
+}
+
+// ChiSquare is the result of a chi-square goodness of fit test
+type ChiSquare struct {
+	Statistic        float64
+	DegreesOfFreedom int
+	// PValue is the chance a uniform shuffle would give a result at least this far from expected
+	PValue float64
+}
+
+// Analyse compares the rounds against a uniform shuffle of the playlist
+//
+// Each round is the order the tracks came up in, only the first depth tracks are used
+func Analyse(playlist []Track, rounds [][]Track, depth int, targetURIs []string) *Report {
+	report := &Report{
+		Rounds: len(rounds),
+		Depth:  depth,
+		Tracks: len(playlist),
+	}
+	if len(playlist) == 0 || len(rounds) == 0 {
+		return report
+	}
+
+	for _, uri := range targetURIs {
+		report.Targets = append(report.Targets, analyseTarget(playlist, rounds, depth, uri))
+	}
+
+	report.Coverage = coverage(playlist, rounds, depth)
+	report.ArtistRepeatRate, report.ExpectedArtistRepeatRate = artistRepeats(playlist, rounds, depth)
+	report.RecencyScore = recency(playlist, rounds, depth)
+	return report
+}
+
+func analyseTarget(playlist []Track, rounds [][]Track, depth int, uri string) *TargetReport {
+	target := &TargetReport{Positions: make([]int, depth)}
+	for _, track := range playlist {
+		if track.URI == uri {
+			target.Track = track
+			break
+		}
+	}
+
+	for _, round := range rounds {
+		found := false
+		for position, track := range limit(round, depth) {
+			if track.URI == uri {
+				target.Positions[position]++
+				found = true
+				break
+			}
+		}
+		if !found {
+			target.Missing++
+		}
+	}
+
+	// With a uniform shuffle every position is equally likely
+	observed := append(append([]int{}, target.Positions...), target.Missing)
+	expected := make([]float64, len(observed))
+	chance := 1 / float64(len(playlist))
+	for i := 0; i < depth; i++ {
+		expected[i] = chance * float64(len(rounds))
+	}
+	expected[depth] = math.Max(0, 1-chance*float64(depth)) * float64(len(rounds))
+	target.ChiSquare = chiSquare(observed, expected)
+
+	return target
+}
+
+// coverage tests whether every track is equally likely to appear within depth
+func coverage(playlist []Track, rounds [][]Track, depth int) ChiSquare {
+	index := make(map[string]int, len(playlist))
+	for i, track := range playlist {
+		index[track.URI] = i
+	}
+
+	observed := make([]int, len(playlist))
+	seen := 0
+	for _, round := range rounds {
+		for _, track := range limit(round, depth) {
+			if i, ok := index[track.URI]; ok {
+				observed[i]++
+				seen++
+			}
+		}
+	}
+
+	expected := make([]float64, len(playlist))
+	for i := range expected {
+		expected[i] = float64(seen) / float64(len(playlist))
+	}
+	return chiSquare(observed, expected)
+}
+
+func artistRepeats(playlist []Track, rounds [][]Track, depth int) (float64, float64) {
+	pairs := 0
+	repeats := 0
+	for _, round := range rounds {
+		tracks := limit(round, depth)
+		for i := 1; i < len(tracks); i++ {
+			pairs++
+			if tracks[i].Artist != "" && tracks[i].Artist == tracks[i-1].Artist {
+				repeats++
+			}
+		}
+	}
+
+	artistCounts := make(map[string]int)
+	for _, track := range playlist {
+		if track.Artist != "" {
+			artistCounts[track.Artist]++
+		}
+	}
+	samePairs := 0
+	for _, count := range artistCounts {
+		samePairs += count * (count - 1)
+	}
+
+	rate := 0.0
+	if pairs > 0 {
+		rate = float64(repeats) / float64(pairs)
+	}
+	expected := 0.0
+	if len(playlist) > 1 {
+		expected = float64(samePairs) / float64(len(playlist)*(len(playlist)-1))
+	}
+	return rate, expected
+}
+
+func recency(playlist []Track, rounds [][]Track, depth int) float64 {
+	sorted := append([]Track{}, playlist...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].AddedAt.Before(sorted[j].AddedAt) })
+
+	percentiles := make(map[string]float64, len(sorted))
+	for i, track := range sorted {
+		if len(sorted) == 1 {
+			percentiles[track.URI] = 0.5
+			continue
+		}
+		percentiles[track.URI] = float64(i) / float64(len(sorted)-1)
+	}
+
+	total := 0.0
+	seen := 0
+	for _, round := range rounds {
+		for _, track := range limit(round, depth) {
+			if percentile, ok := percentiles[track.URI]; ok {
+				total += percentile
+				seen++
+			}
+		}
+	}
+
+	if seen == 0 {
+		return 0.5
+	}
+	return total / float64(seen)
+}
+
+func limit(round []Track, depth int) []Track {
+	if len(round) > depth {
+		return round[:depth]
+	}
+	return round
+}
+
+func chiSquare(observed []int, expected []float64) ChiSquare {
+	statistic := 0.0
+	bins := 0
+	for i := range observed {
+		if expected[i] <= 0 {
+			continue
+		}
+		diff := float64(observed[i]) - expected[i]
+		statistic += diff * diff / expected[i]
+		bins++
+	}
+
+	result := ChiSquare{Statistic: statistic, DegreesOfFreedom: bins - 1, PValue: 1}
+	if result.DegreesOfFreedom > 0 {
+		result.PValue = upperGamma(float64(result.DegreesOfFreedom)/2, statistic/2)
+	}
+	return result
+}
+
+// upperGamma is the regularised upper incomplete gamma function Q(a, x)
+func upperGamma(a float64, x float64) float64 {
+	if x <= 0 {
+		return 1
+	}
+
+	lgamma, _ := math.Lgamma(a)
+	front := math.Exp(-x + a*math.Log(x) - lgamma)
+
+	if x < a+1 {
+		// Series expansion of the lower function
+		sum := 1 / a
+		term := sum
+		for n := 1; n < 500; n++ {
+			term *= x / (a + float64(n))
+			sum += term
+			if term < sum*1e-12 {
+				break
+			}
+		}
+		return math.Max(0, 1-front*sum)
+	}
+
+	// Continued fraction (modified Lentz's method)
+	tiny := 1e-300
+	b := x + 1 - a
+	c := 1 / tiny
+	d := 1 / b
+	h := d
+	for n := 1; n < 500; n++ {
+		an := -float64(n) * (float64(n) - a)
+		b += 2
+		d = an*d + b
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = b + an/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		h *= delta
+		if math.Abs(delta-1) < 1e-12 {
+			break
+		}
+	}
+	return front * h
+}
@@ -0,0 +1,179 @@
+package shuffle
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func closeTo(got float64, want float64, tolerance float64) bool {
+	return math.Abs(got-want) <= tolerance
+}
+
+func TestChiSquarePValue(t *testing.T) {
+	// Critical values from a chi-square table, each has a p value of 0.05 or 0.01
+	tests := []struct {
+		statistic float64
+		freedom   int
+		want      float64
+	}{
+		{3.841, 1, 0.05},
+		{6.635, 1, 0.01},
+		{5.991, 2, 0.05},
+		{11.070, 5, 0.05},
+		{18.307, 10, 0.05},
+		{23.209, 10, 0.01},
+		{67.505, 50, 0.05},
+	}
+
+	for _, test := range tests {
+		got := upperGamma(float64(test.freedom)/2, test.statistic/2)
+		if !closeTo(got, test.want, 0.0005) {
+			t.Errorf("p value of %.3f with %d degrees of freedom = %.5f, want %.2f", test.statistic, test.freedom, got, test.want)
+		}
+	}
+}
+
+func TestUpperGammaExponential(t *testing.T) {
+	// Q(1, x) is e^-x
+	for _, x := range []float64{0.1, 0.5, 1, 2, 5, 10} {
+		if got := upperGamma(1, x); !closeTo(got, math.Exp(-x), 1e-9) {
+			t.Errorf("upperGamma(1, %v) = %v, want %v", x, got, math.Exp(-x))
+		}
+	}
+	if got := upperGamma(3, 0); got != 1 {
+		t.Errorf("upperGamma(3, 0) = %v, want 1", got)
+	}
+}
+
+func TestChiSquare(t *testing.T) {
+	tests := []struct {
+		name          string
+		observed      []int
+		expected      []float64
+		wantStatistic float64
+		wantFreedom   int
+	}{
+		{"exact fit", []int{5, 5, 5, 5}, []float64{5, 5, 5, 5}, 0, 3},
+		{"coin", []int{60, 40}, []float64{50, 50}, 4, 1},
+		{"empty bins are skipped", []int{10, 0, 10}, []float64{10, 0, 10}, 0, 1},
+		{"single bin", []int{7}, []float64{5}, 0.8, 0},
+	}
+
+	for _, test := range tests {
+		got := chiSquare(test.observed, test.expected)
+		if !closeTo(got.Statistic, test.wantStatistic, 1e-9) || got.DegreesOfFreedom != test.wantFreedom {
+			t.Errorf("%s: got statistic %v with %d degrees of freedom, want %v with %d", test.name, got.Statistic, got.DegreesOfFreedom, test.wantStatistic, test.wantFreedom)
+		}
+		if test.wantStatistic == 0 && got.DegreesOfFreedom > 0 && got.PValue != 1 {
+			t.Errorf("%s: a perfect fit should have a p value of 1, got %v", test.name, got.PValue)
+		}
+	}
+}
+
+// rotations returns every rotation of the playlist, so each track is at each position exactly once
+func rotations(playlist []Track) [][]Track {
+	rounds := make([][]Track, len(playlist))
+	for i := range playlist {
+		rounds[i] = append(append([]Track{}, playlist[i:]...), playlist[:i]...)
+	}
+	return rounds
+}
+
+func TestAnalyse(t *testing.T) {
+	playlist := makeTracks("a", "a", "b", "b")
+	for i := range playlist {
+		playlist[i].AddedAt = time.Date(2020, 1, i+1, 0, 0, 0, 0, time.UTC)
+	}
+	a1, a2, b1, b2 := playlist[0], playlist[1], playlist[2], playlist[3]
+
+	tests := []struct {
+		name           string
+		rounds         [][]Track
+		depth          int
+		target         string
+		wantPositions  []int
+		wantMissing    int
+		wantCoverageP  float64
+		wantRepeatRate float64
+		wantRecency    float64
+	}{
+		{
+			name:           "every rotation",
+			rounds:         rotations(playlist),
+			depth:          4,
+			target:         a1.URI,
+			wantPositions:  []int{1, 1, 1, 1},
+			wantMissing:    0,
+			wantCoverageP:  1,
+			wantRepeatRate: 0.5,
+			wantRecency:    0.5,
+		},
+		{
+			name:           "always the same order",
+			rounds:         [][]Track{{b2, a1, b1, a2}, {b2, a1, b1, a2}},
+			depth:          2,
+			target:         b2.URI,
+			wantPositions:  []int{2, 0},
+			wantMissing:    0,
+			wantCoverageP:  -1,
+			wantRepeatRate: 0,
+			wantRecency:    0.5,
+		},
+		{
+			name:           "target never seen",
+			rounds:         [][]Track{{b1, b2}, {b2, b1}},
+			depth:          2,
+			target:         a1.URI,
+			wantPositions:  []int{0, 0},
+			wantMissing:    2,
+			wantCoverageP:  -1,
+			wantRepeatRate: 1,
+			wantRecency:    5.0 / 6,
+		},
+	}
+
+	for _, test := range tests {
+		report := Analyse(playlist, test.rounds, test.depth, []string{test.target})
+		if report.Rounds != len(test.rounds) || report.Tracks != len(playlist) || report.Depth != test.depth {
+			t.Errorf("%s: report has %d rounds, %d tracks and depth %d", test.name, report.Rounds, report.Tracks, report.Depth)
+		}
+		if len(report.Targets) != 1 {
+			t.Fatalf("%s: got %d target reports, want 1", test.name, len(report.Targets))
+		}
+
+		target := report.Targets[0]
+		for i, want := range test.wantPositions {
+			if target.Positions[i] != want {
+				t.Errorf("%s: target positions = %v, want %v", test.name, target.Positions, test.wantPositions)
+				break
+			}
+		}
+		if target.Missing != test.wantMissing {
+			t.Errorf("%s: target missing = %d, want %d", test.name, target.Missing, test.wantMissing)
+		}
+		if test.wantCoverageP >= 0 && !closeTo(report.Coverage.PValue, test.wantCoverageP, 1e-9) {
+			t.Errorf("%s: coverage p value = %v, want %v", test.name, report.Coverage.PValue, test.wantCoverageP)
+		}
+		if test.wantCoverageP < 0 && report.Coverage.PValue > 0.5 {
+			t.Errorf("%s: coverage p value = %v, want an uneven result", test.name, report.Coverage.PValue)
+		}
+		if !closeTo(report.ArtistRepeatRate, test.wantRepeatRate, 1e-9) {
+			t.Errorf("%s: artist repeat rate = %v, want %v", test.name, report.ArtistRepeatRate, test.wantRepeatRate)
+		}
+		if !closeTo(report.RecencyScore, test.wantRecency, 1e-9) {
+			t.Errorf("%s: recency = %v, want %v", test.name, report.RecencyScore, test.wantRecency)
+		}
+		// Two artists with two tracks each, 4 of the 12 ordered pairs share an artist
+		if !closeTo(report.ExpectedArtistRepeatRate, 1.0/3, 1e-9) {
+			t.Errorf("%s: expected artist repeat rate = %v, want 1/3", test.name, report.ExpectedArtistRepeatRate)
+		}
+	}
+}
+
+func TestAnalyseEmpty(t *testing.T) {
+	report := Analyse(nil, nil, 10, []string{"spotify:track:1"})
+	if report.Tracks != 0 || report.Rounds != 0 || len(report.Targets) != 0 {
+		t.Errorf("empty analysis gave %+v", report)
+	}
+}
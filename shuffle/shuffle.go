@@ -0,0 +1,152 @@
+package shuffle
+
+import (
+	"errors"
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Track is the information the shuffle strategies use about each track
+type Track struct {
+	URI     string
+	Name    string
+	Artist  string
+	Album   string
+	AddedAt time.Time
+}
+
+// Strategy returns a new order for the tracks as indexes into tracks
+type Strategy func(tracks []Track, random *rand.Rand) []int
+
+var strategies = map[string]Strategy{
+	"uniform": Uniform,
+	"artist":  ArtistSpread,
+	"album":   AlbumSpread,
+	"fresh":   FreshFirst,
+}
+
+// Get will return the strategy with the given name, an empty name gives the uniform strategy
+func Get(name string) (Strategy, error) {
+	if name == "" {
+		return Uniform, nil
+	}
+	strategy, ok := strategies[strings.ToLower(name)]
+	if !ok {
+		return nil, errors.New("Unknown shuffle mode " + name + ", use one of " + strings.Join(Names(), ", "))
+	}
+	return strategy, nil
+}
+
+// Names returns the names of every strategy
+func Names() []string {
+	names := make([]string, 0, len(strategies))
+	for name := range strategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewRand creates the random source for a shuffle
+//
+// The same seed always gives the same shuffle, an empty seed uses the current time
+func NewRand(seed string) *rand.Rand {
+	if seed == "" {
+		return rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+
+	if number, err := strconv.ParseInt(seed, 10, 64); err == nil {
+		return rand.New(rand.NewSource(number))
+	}
+
+	hash := fnv.New64a()
+	hash.Write([]byte(seed))
+	return rand.New(rand.NewSource(int64(hash.Sum64())))
+}
+
+// Uniform gives every order the same chance
+func Uniform(tracks []Track, random *rand.Rand) []int {
+	return random.Perm(len(tracks))
+}
+
+// ArtistSpread spaces out tracks by the same artist as evenly as possible
+func ArtistSpread(tracks []Track, random *rand.Rand) []int {
+	return spread(tracks, random, func(track Track) string { return track.Artist })
+}
+
+// AlbumSpread spaces out tracks from the same album as evenly as possible
+func AlbumSpread(tracks []Track, random *rand.Rand) []int {
+	return spread(tracks, random, func(track Track) string { return track.Album })
+}
+
+// spread is the balanced dithering approach spotify describes for its own shuffle.
+// Each group's tracks are shuffled then placed at even intervals along the playlist,
+// starting at a random offset and nudged by a small random amount so groups interleave
+// differently every time
+func spread(tracks []Track, random *rand.Rand, key func(Track) string) []int {
+	groups := make(map[string][]int)
+	groupKeys := make([]string, 0)
+	for i, track := range tracks {
+		groupKey := key(track)
+		if _, ok := groups[groupKey]; !ok {
+			groupKeys = append(groupKeys, groupKey)
+		}
+		groups[groupKey] = append(groups[groupKey], i)
+	}
+
+	positions := make([]float64, len(tracks))
+	for _, groupKey := range groupKeys {
+		group := groups[groupKey]
+		random.Shuffle(len(group), func(i, j int) { group[i], group[j] = group[j], group[i] })
+
+		gap := 1 / float64(len(group))
+		offset := random.Float64() * gap
+		for i, index := range group {
+			jitter := (random.Float64() - 0.5) * 0.2 * gap
+			positions[index] = offset + float64(i)*gap + jitter
+		}
+	}
+
+	order := make([]int, len(tracks))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return positions[order[i]] < positions[order[j]] })
+	return order
+}
+
+// freshHalfLife is how long it takes for a track to be half as likely to be picked early
+const freshHalfLife = 90 * 24 * time.Hour
+
+// FreshFirst is a weighted shuffle where recently added tracks tend to come earlier
+func FreshFirst(tracks []Track, random *rand.Rand) []int {
+	newest := time.Time{}
+	for _, track := range tracks {
+		if track.AddedAt.After(newest) {
+			newest = track.AddedAt
+		}
+	}
+
+	// Weighted random sampling without replacement, each track is given the key u^(1/weight)
+	keys := make([]float64, len(tracks))
+	for i, track := range tracks {
+		weight := 0.1
+		if !track.AddedAt.IsZero() {
+			age := newest.Sub(track.AddedAt)
+			weight += math.Pow(0.5, float64(age)/float64(freshHalfLife))
+		}
+		keys[i] = math.Pow(random.Float64(), 1/weight)
+	}
+
+	order := make([]int, len(tracks))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return keys[order[i]] > keys[order[j]] })
+	return order
+}
@@ -0,0 +1,220 @@
+package shuffle
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// makeTracks builds tracks where each entry of artists is the artist of one track
+func makeTracks(artists ...string) []Track {
+	tracks := make([]Track, len(artists))
+	for i, artist := range artists {
+		tracks[i] = Track{URI: "spotify:track:" + strconv.Itoa(i), Artist: artist, Album: artist + " album"}
+	}
+	return tracks
+}
+
+func isPermutation(order []int, length int) bool {
+	if len(order) != length {
+		return false
+	}
+	seen := make([]bool, length)
+	for _, index := range order {
+		if index < 0 || index >= length || seen[index] {
+			return false
+		}
+		seen[index] = true
+	}
+	return true
+}
+
+// longestRun returns the most tracks in a row that share a key
+func longestRun(tracks []Track, order []int, key func(Track) string) int {
+	longest := 0
+	run := 0
+	for i, index := range order {
+		if i > 0 && key(tracks[index]) == key(tracks[order[i-1]]) {
+			run++
+		} else {
+			run = 1
+		}
+		if run > longest {
+			longest = run
+		}
+	}
+	return longest
+}
+
+func TestStrategiesReturnPermutations(t *testing.T) {
+	tests := []struct {
+		name   string
+		tracks []Track
+	}{
+		{"empty", makeTracks()},
+		{"one track", makeTracks("a")},
+		{"one artist", makeTracks("a", "a", "a", "a")},
+		{"mixed", makeTracks("a", "b", "a", "c", "b", "a", "d")},
+	}
+
+	for _, name := range Names() {
+		strategy, err := Get(name)
+		if err != nil {
+			t.Fatalf("Get(%q) returned %v", name, err)
+		}
+		for _, test := range tests {
+			order := strategy(test.tracks, rand.New(rand.NewSource(1)))
+			if !isPermutation(order, len(test.tracks)) {
+				t.Errorf("%s with %s gave %v, not a permutation of %d tracks", name, test.name, order, len(test.tracks))
+			}
+		}
+	}
+}
+
+func TestGet(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{"", false},
+		{"uniform", false},
+		{"Artist", false},
+		{"ALBUM", false},
+		{"fresh", false},
+		{"loud", true},
+	}
+
+	for _, test := range tests {
+		strategy, err := Get(test.name)
+		if (err != nil) != test.wantErr {
+			t.Errorf("Get(%q) error = %v, want error %v", test.name, err, test.wantErr)
+		}
+		if err == nil && strategy == nil {
+			t.Errorf("Get(%q) returned a nil strategy", test.name)
+		}
+	}
+}
+
+func TestNewRandSameSeedSameShuffle(t *testing.T) {
+	for _, seed := range []string{"42", "-7", "road trip"} {
+		first := NewRand(seed).Perm(20)
+		second := NewRand(seed).Perm(20)
+		for i := range first {
+			if first[i] != second[i] {
+				t.Errorf("seed %q gave %v then %v", seed, first, second)
+				break
+			}
+		}
+	}
+}
+
+func TestSpreadSeparatesGroups(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy Strategy
+		key      func(Track) string
+		tracks   []Track
+		maxRun   int
+	}{
+		{
+			name:     "two artists evenly",
+			strategy: ArtistSpread,
+			key:      func(track Track) string { return track.Artist },
+			tracks:   makeTracks("a", "a", "a", "a", "a", "b", "b", "b", "b", "b"),
+			maxRun:   2,
+		},
+		{
+			name:     "three artists",
+			strategy: ArtistSpread,
+			key:      func(track Track) string { return track.Artist },
+			tracks:   makeTracks("a", "a", "a", "a", "b", "b", "b", "b", "c", "c", "c", "c"),
+			maxRun:   2,
+		},
+		{
+			name:     "albums",
+			strategy: AlbumSpread,
+			key:      func(track Track) string { return track.Album },
+			tracks:   makeTracks("a", "a", "a", "b", "b", "b", "c", "c", "c"),
+			maxRun:   2,
+		},
+	}
+
+	for _, test := range tests {
+		for seed := int64(0); seed < 50; seed++ {
+			order := test.strategy(test.tracks, rand.New(rand.NewSource(seed)))
+			if run := longestRun(test.tracks, order, test.key); run > test.maxRun {
+				t.Errorf("%s with seed %d had %d in a row, want at most %d", test.name, seed, run, test.maxRun)
+			}
+		}
+	}
+}
+
+// neighbourRepeats counts the neighbouring tracks that share an artist
+func neighbourRepeats(tracks []Track, order []int) int {
+	repeats := 0
+	for i := 1; i < len(order); i++ {
+		if tracks[order[i]].Artist == tracks[order[i-1]].Artist {
+			repeats++
+		}
+	}
+	return repeats
+}
+
+func TestArtistSpreadRepeatsLessThanUniform(t *testing.T) {
+	tests := []struct {
+		name   string
+		tracks []Track
+	}{
+		{"one artist with most tracks", makeTracks("a", "a", "a", "a", "a", "a", "b", "c", "d")},
+		{"uneven artists", makeTracks("a", "a", "a", "a", "b", "b", "b", "c", "c", "d", "e", "f")},
+	}
+
+	for _, test := range tests {
+		spreadRepeats := 0
+		uniformRepeats := 0
+		random := rand.New(rand.NewSource(1))
+		for round := 0; round < 500; round++ {
+			spreadRepeats += neighbourRepeats(test.tracks, ArtistSpread(test.tracks, random))
+			uniformRepeats += neighbourRepeats(test.tracks, Uniform(test.tracks, random))
+		}
+		if spreadRepeats >= uniformRepeats {
+			t.Errorf("%s: artist spread had %d repeats, uniform had %d", test.name, spreadRepeats, uniformRepeats)
+		}
+	}
+}
+
+func TestFreshFirstPrefersRecentTracks(t *testing.T) {
+	now := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		ages []time.Duration
+	}{
+		{"a week against two years", []time.Duration{7 * 24 * time.Hour, 2 * 365 * 24 * time.Hour}},
+		{"new against a year", []time.Duration{0, 365 * 24 * time.Hour}},
+	}
+
+	for _, test := range tests {
+		// Pad the playlist with old tracks so positions have room to differ
+		tracks := make([]Track, 0)
+		for i, age := range test.ages {
+			tracks = append(tracks, Track{URI: "track" + strconv.Itoa(i), AddedAt: now.Add(-age)})
+		}
+		for i := 0; i < 20; i++ {
+			tracks = append(tracks, Track{URI: "filler" + strconv.Itoa(i), AddedAt: now.Add(-3 * 365 * 24 * time.Hour)})
+		}
+
+		totals := make([]int, len(test.ages))
+		random := rand.New(rand.NewSource(1))
+		for round := 0; round < 500; round++ {
+			for position, index := range FreshFirst(tracks, random) {
+				if index < len(totals) {
+					totals[index] += position
+				}
+			}
+		}
+		if totals[0] >= totals[1] {
+			t.Errorf("%s: newer track averaged position %.1f, older %.1f", test.name, float64(totals[0])/500, float64(totals[1])/500)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package spotify
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Artist represents a spotify artist
+type Artist struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	URI        string   `json:"uri"`
+	Genres     []string `json:"genres"`
+	Popularity int      `json:"popularity"`
+	Followers  struct {
+		Total int `json:"total"`
+	} `json:"followers"`
+}
+
+// GetArtists will get the details of the given artists with no limit, keyed by artist ID
+func GetArtists(artistIDs []string) map[string]*Artist {
+	artists := make(map[string]*Artist, len(artistIDs))
+	limit := 50
+
+	for offset := 0; offset < len(artistIDs); offset += limit {
+		max := offset + limit
+		if max > len(artistIDs) {
+			max = len(artistIDs)
+		}
+
+		url := fmt.Sprintf("https://api.spotify.com/v1/artists?ids=%s", strings.Join(artistIDs[offset:max], ","))
+		res := &artistsRes{}
+		err := tryMakeReq("GET", url, res)
+		if !handleError(err) {
+			return nil
+		}
+
+		for _, artist := range res.Artists {
+			if artist != nil {
+				artists[artist.ID] = artist
+			}
+		}
+	}
+	return artists
+}
+
+// Album is an album, single or compilation by an artist
+type Album struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	URI         string `json:"uri"`
+	AlbumType   string `json:"album_type"`
+	AlbumGroup  string `json:"album_group"`
+	ReleaseDate string `json:"release_date"`
+	TotalTracks int    `json:"total_tracks"`
+}
+
+// GetFollowedArtists will get every artist the user follows
+func GetFollowedArtists() []*Artist {
+	artists := make([]*Artist, 0)
+
+	url := "https://api.spotify.com/v1/me/following?type=artist&limit=50"
+	for url != "" {
+		res := &followedArtistsRes{}
+		err := tryMakeReq("GET", url, res)
+		if !handleError(err) {
+			return nil
+		}
+
+		artists = append(artists, res.Artists.Items...)
+		// Followed artists are paged by cursor, next already has the after cursor set
+		url = res.Artists.Next
+	}
+	return artists
+}
+
+// IsFollowingArtist will check if the user follows the artist
+func IsFollowingArtist(artistID string) (bool, error) {
+	url := fmt.Sprintf("https://api.spotify.com/v1/me/following/contains?type=artist&ids=%s", artistID)
+	res := make([]bool, 0, 1)
+	err := tryMakeReq("GET", url, &res)
+	if !handleError(err) || len(res) == 0 {
+		return false, errors.New("Could not check if you follow the artist")
+	}
+	return res[0], nil
+}
+
+// FollowArtist will follow the artist
+func FollowArtist(artistID string) error {
+	url := fmt.Sprintf("https://api.spotify.com/v1/me/following?type=artist&ids=%s", artistID)
+	err := tryMakeReq("PUT", url, nil)
+	if !handleError(err) {
+		return errors.New("Could not follow the artist")
+	}
+	return nil
+}
+
+// UnfollowArtist will stop following the artist
+func UnfollowArtist(artistID string) error {
+	url := fmt.Sprintf("https://api.spotify.com/v1/me/following?type=artist&ids=%s", artistID)
+	err := tryMakeReq("DELETE", url, nil)
+	if !handleError(err) {
+		return errors.New("Could not unfollow the artist")
+	}
+	return nil
+}
+
+// GetArtistTopTracks will get the most popular tracks of the artist
+//
+// Limit 10
+func GetArtistTopTracks(artistID string) []*Track {
+	url := fmt.Sprintf("https://api.spotify.com/v1/artists/%s/top-tracks?market=NZ", artistID)
+	res := &tracksRes{}
+	err := tryMakeReq("GET", url, res)
+	if !handleError(err) {
+		return nil
+	}
+	return res.Tracks
+}
+
+// GetRelatedArtists will get artists similar to the artist
+//
+// Limit 20
+func GetRelatedArtists(artistID string) []*Artist {
+	url := fmt.Sprintf("https://api.spotify.com/v1/artists/%s/related-artists", artistID)
+	res := &artistsRes{}
+	err := tryMakeReq("GET", url, res)
+	if !handleError(err) {
+		return nil
+	}
+	return res.Artists
+}
+
+// GetArtistAlbums will get every release of the artist in the given groups (album, single, appears_on or compilation)
+func GetArtistAlbums(artistID string, groups []string) []*Album {
+	albums := make([]*Album, 0)
+
+	url := fmt.Sprintf("https://api.spotify.com/v1/artists/%s/albums?include_groups=%s&market=NZ&limit=50", artistID, strings.Join(groups, ","))
+	for url != "" {
+		res := &albumsRes{}
+		err := tryMakeReq("GET", url, res)
+		if !handleError(err) {
+			return nil
+		}
+
+		albums = append(albums, res.Items...)
+		url = res.Next
+	}
+	return albums
+}
+
+// GetAlbumTracks will get every track on the album, the tracks don't include the album
+func GetAlbumTracks(albumID string) []*Track {
+	tracks := make([]*Track, 0)
+
+	url := fmt.Sprintf("https://api.spotify.com/v1/albums/%s/tracks?market=NZ&limit=50", albumID)
+	for url != "" {
+		res := &albumTracksRes{}
+		err := tryMakeReq("GET", url, res)
+		if !handleError(err) {
+			return nil
+		}
+
+		tracks = append(tracks, res.Items...)
+		url = res.Next
+	}
+	return tracks
+}
+
+type artistsRes struct {
+	Artists []*Artist `json:"artists"`
+}
+
+type followedArtistsRes struct {
+	Artists struct {
+		Items   []*Artist `json:"items"`
+		Next    string    `json:"next"`
+		Cursors Cursors   `json:"cursors"`
+	} `json:"artists"`
+}
+
+type albumsRes struct {
+	Items []*Album `json:"items"`
+	Next  string   `json:"next"`
+}
+
+type albumTracksRes struct {
+	Items []*Track `json:"items"`
+	Next  string   `json:"next"`
+}
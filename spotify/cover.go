@@ -0,0 +1,27 @@
+package spotify
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// MaxCoverSize is the largest base64 encoded cover image spotify accepts, in bytes
+const MaxCoverSize = 256 * 1024
+
+// UploadPlaylistCover will set the cover of the playlist to the given JPEG image
+//
+// Limit 256KB once base64 encoded
+func UploadPlaylistCover(playlistID string, jpegImage []byte) error {
+	encoded := base64.StdEncoding.EncodeToString(jpegImage)
+	if len(encoded) > MaxCoverSize {
+		return fmt.Errorf("The cover image is %dKB, it must be under %dKB", len(encoded)/1024, MaxCoverSize/1024)
+	}
+
+	url := fmt.Sprintf("https://api.spotify.com/v1/playlists/%s/images", playlistID)
+	err := tryMakeRawReq("PUT", url, "image/jpeg", []byte(encoded))
+	if !handleError(err) {
+		return errors.New("Could not upload the cover image")
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package spotify
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AudioFeatures are spotify's audio analysis values for a track
+type AudioFeatures struct {
+	ID               string  `json:"id"`
+	Acousticness     float64 `json:"acousticness"`
+	Danceability     float64 `json:"danceability"`
+	Energy           float64 `json:"energy"`
+	Instrumentalness float64 `json:"instrumentalness"`
+	Key              int     `json:"key"`
+	Liveness         float64 `json:"liveness"`
+	Loudness         float64 `json:"loudness"`
+	Mode             int     `json:"mode"`
+	Speechiness      float64 `json:"speechiness"`
+	Tempo            float64 `json:"tempo"`
+	TimeSignature    int     `json:"time_signature"`
+	Valence          float64 `json:"valence"`
+}
+
+// GetAudioFeatures will get the audio features for the given tracks with no limit, keyed by track ID
+//
+// Tracks without features (such as local files) are left out
+func GetAudioFeatures(trackIDs []string) map[string]*AudioFeatures {
+	features := make(map[string]*AudioFeatures, len(trackIDs))
+	limit := 100
+
+	for offset := 0; offset < len(trackIDs); offset += limit {
+		max := offset + limit
+		if max > len(trackIDs) {
+			max = len(trackIDs)
+		}
+
+		url := fmt.Sprintf("https://api.spotify.com/v1/audio-features?ids=%s", strings.Join(trackIDs[offset:max], ","))
+		res := &audioFeaturesRes{}
+		err := tryMakeReq("GET", url, res)
+		if !handleError(err) {
+			return nil
+		}
+
+		for _, feature := range res.AudioFeatures {
+			if feature != nil {
+				features[feature.ID] = feature
+			}
+		}
+	}
+	return features
+}
+
+type audioFeaturesRes struct {
+	AudioFeatures []*AudioFeatures `json:"audio_features"`
+}
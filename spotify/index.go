@@ -0,0 +1,67 @@
+package spotify
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TrackLocation is where a track is in a playlist
+type TrackLocation struct {
+	Position int
+	AddedAt  time.Time
+}
+
+// trackIndex is the location of every track in a playlist, keyed by track ID
+type trackIndex struct {
+	SnapshotID string
+	Tracks     map[string][]*TrackLocation
+}
+
+var trackIndexMutex sync.Mutex
+var trackIndexes = make(map[string]*trackIndex)
+
+// FindTrackInPlaylist will return every location of the track in the playlist
+//
+// The playlist's tracks are cached and only fetched again once its snapshot ID changes
+func FindTrackInPlaylist(playlistID string, songURI string) ([]*TrackLocation, error) {
+	playlist := GetPlaylist(playlistID)
+	if playlist == nil {
+		return nil, errors.New("Could not get playlist")
+	}
+
+	trackIndexMutex.Lock()
+	index := trackIndexes[playlistID]
+	trackIndexMutex.Unlock()
+
+	if index == nil || index.SnapshotID != playlist.SnapshotID {
+		items := GetTracksInPlaylist(playlistID)
+		if items == nil {
+			return nil, errors.New("Could not get tracks for " + playlist.Name)
+		}
+
+		index = &trackIndex{SnapshotID: playlist.SnapshotID, Tracks: make(map[string][]*TrackLocation)}
+		for position, item := range items {
+			if item == nil {
+				continue
+			}
+			key := indexKey(item.Track.URI)
+			index.Tracks[key] = append(index.Tracks[key], &TrackLocation{Position: position, AddedAt: item.AddedAt})
+		}
+
+		trackIndexMutex.Lock()
+		trackIndexes[playlistID] = index
+		trackIndexMutex.Unlock()
+	}
+
+	return index.Tracks[indexKey(songURI)], nil
+}
+
+// indexKey is the track ID for spotify tracks and the whole URI for local files
+func indexKey(songURI string) string {
+	if strings.HasPrefix(songURI, "spotify:track:") {
+		return strings.TrimPrefix(songURI, "spotify:track:")
+	}
+	return songURI
+}
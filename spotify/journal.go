@@ -0,0 +1,193 @@
+package spotify
+
+import (
+	"errors"
+	"sort"
+	"sync"
+)
+
+// JournalEntry is a change made to a playlist during this session
+type JournalEntry struct {
+	// Action is either "add" or "remove"
+	Action     string
+	PlaylistID string
+	URIs       []string
+	// Positions holds the position in the playlist of each URI before it was removed or after it was added
+	Positions []int
+}
+
+var journalMutex sync.Mutex
+var undoJournal = make([]*JournalEntry, 0)
+var redoJournal = make([]*JournalEntry, 0)
+
+func recordChange(entry *JournalEntry) {
+	journalMutex.Lock()
+	defer journalMutex.Unlock()
+
+	undoJournal = append(undoJournal, entry)
+	redoJournal = redoJournal[:0]
+}
+
+// forgetChanges drops the journal entries for the playlist, used when its tracks are moved or replaced without
+// being journalled as undoing them would then add or remove the wrong tracks
+func forgetChanges(playlistID string) {
+	journalMutex.Lock()
+	defer journalMutex.Unlock()
+
+	undoJournal = withoutPlaylist(undoJournal, playlistID)
+	redoJournal = withoutPlaylist(redoJournal, playlistID)
+}
+
+func withoutPlaylist(journal []*JournalEntry, playlistID string) []*JournalEntry {
+	kept := make([]*JournalEntry, 0, len(journal))
+	for _, entry := range journal {
+		if entry.PlaylistID != playlistID {
+			kept = append(kept, entry)
+		}
+	}
+	return kept
+}
+
+// NextUndo returns the change Undo would reverse, or nil if there is nothing to undo
+func NextUndo() *JournalEntry {
+	journalMutex.Lock()
+	defer journalMutex.Unlock()
+
+	if len(undoJournal) == 0 {
+		return nil
+	}
+	return undoJournal[len(undoJournal)-1]
+}
+
+// NextRedo returns the change Redo would make again, or nil if there is nothing to redo
+func NextRedo() *JournalEntry {
+	journalMutex.Lock()
+	defer journalMutex.Unlock()
+
+	if len(redoJournal) == 0 {
+		return nil
+	}
+	return redoJournal[len(redoJournal)-1]
+}
+
+// Undo will reverse the last playlist change made this session and return it
+func Undo() (*JournalEntry, error) {
+	journalMutex.Lock()
+	defer journalMutex.Unlock()
+
+	if len(undoJournal) == 0 {
+		return nil, errors.New("Nothing to undo")
+	}
+
+	entry := undoJournal[len(undoJournal)-1]
+	var err error
+	if entry.Action == "add" {
+		err = removeAtPositions(entry.PlaylistID, entry.URIs, entry.Positions)
+	} else {
+		err = insertAtPositions(entry.PlaylistID, entry.URIs, entry.Positions)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	undoJournal = undoJournal[:len(undoJournal)-1]
+	redoJournal = append(redoJournal, entry)
+	return entry, nil
+}
+
+// Redo will make the last undone playlist change again and return it
+func Redo() (*JournalEntry, error) {
+	journalMutex.Lock()
+	defer journalMutex.Unlock()
+
+	if len(redoJournal) == 0 {
+		return nil, errors.New("Nothing to redo")
+	}
+
+	entry := redoJournal[len(redoJournal)-1]
+	var err error
+	if entry.Action == "add" {
+		err = insertAtPositions(entry.PlaylistID, entry.URIs, entry.Positions)
+	} else {
+		err = removeAtPositions(entry.PlaylistID, entry.URIs, entry.Positions)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	redoJournal = redoJournal[:len(redoJournal)-1]
+	undoJournal = append(undoJournal, entry)
+	return entry, nil
+}
+
+type positionedURI struct {
+	URI      string
+	Position int
+}
+
+func sortedByPosition(uris []string, positions []int) []positionedURI {
+	sorted := make([]positionedURI, len(uris))
+	for i := range uris {
+		sorted[i] = positionedURI{URI: uris[i], Position: positions[i]}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Position < sorted[j].Position })
+	return sorted
+}
+
+// insertAtPositions will put each URI back at its position, runs of consecutive positions are added together
+func insertAtPositions(playlistID string, uris []string, positions []int) error {
+	sorted := sortedByPosition(uris, positions)
+
+	start := 0
+	for start < len(sorted) {
+		end := start + 1
+		for end < len(sorted) && end-start < 100 && sorted[end].Position == sorted[end-1].Position+1 {
+			end++
+		}
+
+		run := make([]string, end-start)
+		for i := range run {
+			run[i] = sorted[start+i].URI
+		}
+		err := insertIntoPlaylist(playlistID, run, sorted[start].Position)
+		if err != nil {
+			return err
+		}
+		start = end
+	}
+	return nil
+}
+
+// removeAtPositions will remove the URIs at the given positions
+//
+// Removes the highest positions first so the earlier positions don't move between requests
+func removeAtPositions(playlistID string, uris []string, positions []int) error {
+	sorted := sortedByPosition(uris, positions)
+
+	end := len(sorted)
+	for end > 0 {
+		start := end - 100
+		if start < 0 {
+			start = 0
+		}
+
+		// Each URI can only be given once per request so duplicates share a positions list
+		tracks := make([]deletePlaylistBody, 0, end-start)
+		trackIndex := make(map[string]int)
+		for _, item := range sorted[start:end] {
+			index, ok := trackIndex[item.URI]
+			if !ok {
+				index = len(tracks)
+				trackIndex[item.URI] = index
+				tracks = append(tracks, deletePlaylistBody{URI: item.URI, Positions: []int{}})
+			}
+			tracks[index].Positions = append(tracks[index].Positions, item.Position)
+		}
+		err := removeTracks(playlistID, tracks)
+		if err != nil {
+			return err
+		}
+		end = start
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package spotify
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/rocketbang/spotify-controller/config"
+)
+
+// PlayHistoryItem is a track the user has played
+type PlayHistoryItem struct {
+	Track    Track     `json:"track"`
+	PlayedAt time.Time `json:"played_at"`
+	Context  *struct {
+		Type string `json:"type"`
+		URI  string `json:"uri"`
+	} `json:"context"`
+}
+
+// Cursors are the positions to use to get the next or previous page of a cursor paged result
+type Cursors struct {
+	After  string `json:"after"`
+	Before string `json:"before"`
+}
+
+// GetLikedTracks gets all the tracks in the users Liked Songs or nil if any page could not be fetched
+func GetLikedTracks() []*PlaylistTrackResItem {
+	url := "https://api.spotify.com/v1/me/tracks?market=NZ&limit=50"
+	res := &playlistTrackRes{}
+
+	if config.Value.Debug {
+		fmt.Printf("fetching: %s\n", url)
+	}
+
+	err := tryMakeReq("GET", url, res)
+	if !handleError(err) {
+		return nil
+	}
+
+	return getPagesAsync(url, res, &playlistTrackRes{})
+}
+
+// GetTopTracks gets the users top tracks for the given time range (short, medium or long)
+func GetTopTracks(timeRange string) []*Track {
+	tracks := make([]*Track, 0)
+
+	url := fmt.Sprintf("https://api.spotify.com/v1/me/top/tracks?time_range=%s_term&limit=50", timeRange)
+	for url != "" {
+		res := &topTracksRes{}
+		err := tryMakeReq("GET", url, res)
+		if !handleError(err) {
+			return nil
+		}
+
+		for i := range res.Items {
+			tracks = append(tracks, &res.Items[i])
+		}
+		url = res.Next
+	}
+	return tracks
+}
+
+// GetTopArtists gets the users top artists for the given time range (short, medium or long)
+func GetTopArtists(timeRange string) []*Artist {
+	artists := make([]*Artist, 0)
+
+	url := fmt.Sprintf("https://api.spotify.com/v1/me/top/artists?time_range=%s_term&limit=50", timeRange)
+	for url != "" {
+		res := &topArtistsRes{}
+		err := tryMakeReq("GET", url, res)
+		if !handleError(err) {
+			return nil
+		}
+
+		artists = append(artists, res.Items...)
+		url = res.Next
+	}
+	return artists
+}
+
+// GetRecentlyPlayed gets up to limit (max 50) of the users most recently played tracks
+//
+// Only tracks played before the before time or after the after time are returned, leave both zero for the latest plays
+func GetRecentlyPlayed(limit int, before time.Time, after time.Time) ([]*PlayHistoryItem, *Cursors) {
+	url := fmt.Sprintf("https://api.spotify.com/v1/me/player/recently-played?limit=%d", limit)
+	if !before.IsZero() {
+		url += "&before=" + strconv.FormatInt(before.UnixNano()/int64(time.Millisecond), 10)
+	} else if !after.IsZero() {
+		url += "&after=" + strconv.FormatInt(after.UnixNano()/int64(time.Millisecond), 10)
+	}
+
+	res := &recentlyPlayedRes{}
+	err := tryMakeReq("GET", url, res)
+	if !handleError(err) {
+		return nil, nil
+	}
+
+	items := make([]*PlayHistoryItem, len(res.Items))
+	for i := range res.Items {
+		items[i] = &res.Items[i]
+	}
+	return items, &res.Cursors
+}
+
+type topTracksRes struct {
+	Paging
+	Items []Track `json:"items"`
+}
+
+type topArtistsRes struct {
+	Paging
+	Items []*Artist `json:"items"`
+}
+
+type recentlyPlayedRes struct {
+	Items   []PlayHistoryItem `json:"items"`
+	Next    string            `json:"next"`
+	Cursors Cursors           `json:"cursors"`
+	Limit   int               `json:"limit"`
+}
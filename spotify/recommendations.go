@@ -0,0 +1,53 @@
+package spotify
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// MaxRecommendationSeeds is the most seed tracks, artists and genres that can be used together
+const MaxRecommendationSeeds = 5
+
+// RecommendationSeeds are the tracks, artists and genres recommendations are based on
+type RecommendationSeeds struct {
+	TrackIDs  []string
+	ArtistIDs []string
+	Genres    []string
+}
+
+// GetRecommendations will get tracks like the seeds
+//
+// Targets are audio features such as energy or tempo, and popularity, that the tracks should be close to.
+// Limit 100
+func GetRecommendations(seeds *RecommendationSeeds, targets map[string]float64, limit int) ([]*Track, error) {
+	seedCount := len(seeds.TrackIDs) + len(seeds.ArtistIDs) + len(seeds.Genres)
+	if seedCount == 0 || seedCount > MaxRecommendationSeeds {
+		return nil, errors.New("Recommendations need between 1 and 5 seeds")
+	}
+
+	query := url.Values{}
+	query.Set("market", "NZ")
+	query.Set("limit", strconv.Itoa(limit))
+	if len(seeds.TrackIDs) > 0 {
+		query.Set("seed_tracks", strings.Join(seeds.TrackIDs, ","))
+	}
+	if len(seeds.ArtistIDs) > 0 {
+		query.Set("seed_artists", strings.Join(seeds.ArtistIDs, ","))
+	}
+	if len(seeds.Genres) > 0 {
+		query.Set("seed_genres", strings.Join(seeds.Genres, ","))
+	}
+	for name, value := range targets {
+		query.Set("target_"+name, strconv.FormatFloat(value, 'f', -1, 64))
+	}
+
+	reqURL := "https://api.spotify.com/v1/recommendations?" + query.Encode()
+	res := &tracksRes{}
+	err := tryMakeReq("GET", reqURL, res)
+	if !handleError(err) {
+		return nil, errors.New("Could not get recommendations")
+	}
+	return res.Tracks, nil
+}
@@ -9,12 +9,19 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/rocketbang/spotify-controller/config"
 )
 
 var authRes *AuthResult
 
+// authMutex guards authRes, requests are made from background goroutines as well as commands
+var authMutex sync.Mutex
+
+// Client is the HTTP client every request is sent with, it can be replaced to send requests to a stub server
+var Client = &http.Client{}
+
 func makeSimpleReq(method string, url string, bodyBuffer *bytes.Buffer) (*http.Request, error) {
 	if bodyBuffer == nil {
 		return http.NewRequest(method, url, nil)
@@ -23,27 +30,71 @@ func makeSimpleReq(method string, url string, bodyBuffer *bytes.Buffer) (*http.R
 	return http.NewRequest(method, url, bodyBuffer)
 }
 
-func makeAuthReq(method string, url string, bodyBuffer *bytes.Buffer) *http.Response {
-	client := &http.Client{}
-	req, _ := makeSimpleReq(method, url, bodyBuffer)
+func makeAuthReq(method string, url string, bodyBuffer *bytes.Buffer) (*http.Response, error) {
+	return makeAuthReqWithType(method, url, bodyBuffer, "application/json")
+}
 
-	// TODO more error handling
+// makeAuthReqWithType returns an error if spotify couldn't be reached, such as when offline
+func makeAuthReqWithType(method string, url string, bodyBuffer *bytes.Buffer, contentType string) (*http.Response, error) {
+	client := Client
+	req, err := makeSimpleReq(method, url, bodyBuffer)
+	if err != nil {
+		return nil, err
+	}
 
-	req.Header.Add("Authorization", "Bearer "+authRes.AccessToken)
-	req.Header.Add("Content-Type", "application/json")
+	token := accessToken()
+	req.Header.Add("Authorization", "Bearer "+token)
+	req.Header.Add("Content-Type", contentType)
 
-	res, _ := client.Do(req)
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
 
 	if res.StatusCode == 401 {
-		log.Println("Refreshing...")
-		refresh(authRes.RefreshToken)
+		res.Body.Close()
+		refreshIfExpired(token)
+
+		req2, err := makeSimpleReq(method, url, bodyBuffer)
+		if err != nil {
+			return nil, err
+		}
+		req2.Header.Add("Authorization", "Bearer "+accessToken())
+		req2.Header.Add("Content-Type", contentType)
+		res, err = client.Do(req2)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
+
+func accessToken() string {
+	authMutex.Lock()
+	defer authMutex.Unlock()
 
-		req2, _ := makeSimpleReq(method, url, bodyBuffer)
-		req2.Header.Add("Authorization", "Bearer "+authRes.AccessToken)
-		res, _ = client.Do(req2)
+	if authRes == nil {
+		return ""
 	}
+	return authRes.AccessToken
+}
+
+// refreshIfExpired refreshes the access token unless another request already replaced the expired one
+func refreshIfExpired(expiredToken string) {
+	authMutex.Lock()
+	defer authMutex.Unlock()
+
+	if authRes == nil || authRes.AccessToken != expiredToken {
+		return
+	}
+	log.Println("Refreshing...")
+	refresh(authRes.RefreshToken)
+}
 
-	return res
+// reqFailed is the error result used when a request couldn't be sent at all
+func reqFailed(err error) *ErrorResult {
+	return &ErrorResult{Error: ErrorStatus{Message: err.Error()}}
 }
 
 func tryMakeReq(method string, url string, result interface{}) *ErrorResult {
@@ -51,6 +102,15 @@ func tryMakeReq(method string, url string, result interface{}) *ErrorResult {
 }
 
 func tryMakeReq2(method string, url string, result interface{}, body interface{}) *ErrorResult {
+	return sendJSONReq(method, url, result, body, false)
+}
+
+// tryMakeQuietReq is tryMakeReq without printing failures, for requests made in the background
+func tryMakeQuietReq(method string, url string, result interface{}) *ErrorResult {
+	return sendJSONReq(method, url, result, nil, true)
+}
+
+func sendJSONReq(method string, url string, result interface{}, body interface{}, quiet bool) *ErrorResult {
 	var bodyBytes *bytes.Buffer = nil
 	if body != nil {
 		body, err := json.Marshal(body)
@@ -61,15 +121,20 @@ func tryMakeReq2(method string, url string, result interface{}, body interface{}
 		bodyBytes = bytes.NewBuffer(body)
 	}
 
-	resp := makeAuthReq(method, url, bodyBytes)
+	resp, err := makeAuthReq(method, url, bodyBytes)
+	if err != nil {
+		return reqFailed(err)
+	}
 
 	if resp.StatusCode < 200 || resp.StatusCode > 299 {
 		errorResult := &ErrorResult{}
 		buf := new(bytes.Buffer)
 		buf.ReadFrom(resp.Body)
-		newStr := buf.String()
-		fmt.Printf("Failed req with code %d %s\n", resp.StatusCode, newStr)
-		json.NewDecoder(resp.Body).Decode(errorResult)
+		resp.Body.Close()
+		if !quiet {
+			fmt.Printf("Failed req with code %d %s\n", resp.StatusCode, buf.String())
+		}
+		json.Unmarshal(buf.Bytes(), errorResult)
 		return errorResult
 	}
 
@@ -81,10 +146,30 @@ func tryMakeReq2(method string, url string, result interface{}, body interface{}
 	return nil
 }
 
+// tryMakeRawReq sends the body as it is rather than as JSON
+func tryMakeRawReq(method string, url string, contentType string, body []byte) *ErrorResult {
+	resp, err := makeAuthReqWithType(method, url, bytes.NewBuffer(body), contentType)
+	if err != nil {
+		return reqFailed(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		errorResult := &ErrorResult{}
+		buf := new(bytes.Buffer)
+		buf.ReadFrom(resp.Body)
+		fmt.Printf("Failed req with code %d %s\n", resp.StatusCode, buf.String())
+		json.Unmarshal(buf.Bytes(), errorResult)
+		return errorResult
+	}
+	return nil
+}
+
+// refresh gets a new access token, authMutex must be held
 func refresh(refreshToken string) {
 	config := config.Value
 
-	client := &http.Client{}
+	client := Client
 
 	data := url.Values{}
 	data.Set("refresh_token", refreshToken)
@@ -104,10 +189,12 @@ func refresh(refreshToken string) {
 
 	resp, err := client.Do(req)
 
+	// Not fatal as it can happen in the background while offline, the request will just fail
 	if err != nil {
-		log.Fatal("Could not send refresh token request")
+		fmt.Println("Could not send refresh token request")
 		return
 	}
+	defer resp.Body.Close()
 
 	if resp.StatusCode == 400 {
 		errorResult := &ErrorResult{}
@@ -128,7 +215,7 @@ func refresh(refreshToken string) {
 func Authorise(code string) {
 	config := config.Value
 
-	client := &http.Client{}
+	client := Client
 
 	data := url.Values{}
 	data.Set("code", code)
@@ -167,7 +254,9 @@ func Authorise(code string) {
 		fmt.Println(result)
 	}
 
+	authMutex.Lock()
 	authRes = result
+	authMutex.Unlock()
 }
 
 // AuthResult is the result from the spotify auth method
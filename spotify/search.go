@@ -0,0 +1,68 @@
+package spotify
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// SearchTracks will search spotify for tracks matching the given query
+//
+// Limit 50
+func SearchTracks(query string, limit int) []*Track {
+	reqURL := fmt.Sprintf("https://api.spotify.com/v1/search?type=track&market=NZ&limit=%d&q=%s", limit, url.QueryEscape(query))
+	res := &searchRes{}
+
+	err := tryMakeReq("GET", reqURL, res)
+	if !handleError(err) {
+		return nil
+	}
+
+	tracks := make([]*Track, len(res.Tracks.Items))
+	for i := range res.Tracks.Items {
+		tracks[i] = &res.Tracks.Items[i]
+	}
+	return tracks
+}
+
+// SearchTrackByISRC will return the first track with the given ISRC or nil if there is none
+func SearchTrackByISRC(isrc string) *Track {
+	tracks := SearchTracks("isrc:"+isrc, 1)
+	if len(tracks) == 0 {
+		return nil
+	}
+	return tracks[0]
+}
+
+type searchRes struct {
+	Tracks struct {
+		Paging
+		Href  string  `json:"href"`
+		Items []Track `json:"items"`
+	} `json:"tracks"`
+}
+
+// GetTracks will get the details of the tracks with the given IDs
+//
+// Limit 50
+func GetTracks(trackIDs []string) []*Track {
+	reqURL := fmt.Sprintf("https://api.spotify.com/v1/tracks?market=NZ&ids=%s", strings.Join(trackIDs, ","))
+	res := &tracksRes{}
+
+	err := tryMakeReq("GET", reqURL, res)
+	if !handleError(err) {
+		return nil
+	}
+
+	tracks := make([]*Track, 0, len(res.Tracks))
+	for i := range res.Tracks {
+		if res.Tracks[i] != nil {
+			tracks = append(tracks, res.Tracks[i])
+		}
+	}
+	return tracks
+}
+
+type tracksRes struct {
+	Tracks []*Track `json:"tracks"`
+}
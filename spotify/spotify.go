@@ -46,11 +46,13 @@ func PlayPlaylist(playlistURI string) {
 
 // PlayTracks will play the given tracks
 // Has a maximum limit of 800 (any extra will not be included)
-func PlayTracks(songURIs []string, playlistURI string) {
+//
+// Returns the number of tracks that were sent to spotify
+func PlayTracks(songURIs []string, playlistURI string) int {
 	// This maximum isn't documented but the PlayTracks request will constantly fail if max is ~900 so I've set it to 800
 	max := 800
-	if max > len(songURIs)-1 {
-		max = len(songURIs) - 1
+	if max > len(songURIs) {
+		max = len(songURIs)
 	}
 
 	body := &playReq{
@@ -59,9 +61,20 @@ func PlayTracks(songURIs []string, playlistURI string) {
 
 	err := tryMakeReq2("PUT", "https://api.spotify.com/v1/me/player/play", nil, body)
 	if !handleError(err) {
-		return
+		return 0
 	}
 
+	return max
+}
+
+// AddToQueue will add the given song to the end of the play queue
+func AddToQueue(songURI string) error {
+	url := fmt.Sprintf("https://api.spotify.com/v1/me/player/queue?uri=%s", songURI)
+	err := tryMakeReq("POST", url, nil)
+	if !handleError(err) {
+		return errors.New("Could not add to queue")
+	}
+	return nil
 }
 
 // Next will go to the next track
@@ -74,24 +87,81 @@ func Prev() {
 	makeAuthReq("POST", "https://api.spotify.com/v1/me/player/previous", nil)
 }
 
-// GetPlaylists will get the playlists for the current spotify user
-func GetPlaylists() []*Playlist {
-	playlists := &playlistReq{}
-	err := tryMakeReq("GET", "https://api.spotify.com/v1/me/playlists", playlists)
+// GetQueue will get the currently playing track followed by the tracks queued after it
+//
+// Spotify only returns around 20 queued tracks
+func GetQueue() []*Track {
+	res := &queueRes{}
+	err := tryMakeReq("GET", "https://api.spotify.com/v1/me/player/queue", res)
 	if !handleError(err) {
 		return nil
 	}
-	convertedPlaylists := make([]*Playlist, len(playlists.Items))
-	for i, playlist := range playlists.Items {
-		convertedPlaylists[i] = &Playlist{
-			ID:   playlist.ID,
-			Name: playlist.Name,
-			URI:  playlist.URI,
+
+	tracks := make([]*Track, 0, len(res.Queue)+1)
+	if res.CurrentlyPlaying != nil {
+		tracks = append(tracks, res.CurrentlyPlaying)
+	}
+	for i := range res.Queue {
+		tracks = append(tracks, &res.Queue[i])
+	}
+	return tracks
+}
+
+// GetPlaylists will get the playlists for the current spotify user
+func GetPlaylists() []*Playlist {
+	convertedPlaylists := make([]*Playlist, 0)
+
+	url := "https://api.spotify.com/v1/me/playlists?limit=50"
+	for url != "" {
+		playlists := &playlistReq{}
+		err := tryMakeReq("GET", url, playlists)
+		if !handleError(err) {
+			return nil
 		}
+
+		for _, playlist := range playlists.Items {
+			convertedPlaylists = append(convertedPlaylists, &Playlist{
+				ID:            playlist.ID,
+				Name:          playlist.Name,
+				URI:           playlist.URI,
+				SnapshotID:    playlist.SnapshotID,
+				TrackCount:    playlist.Tracks.Total,
+				Description:   playlist.Description,
+				OwnerID:       playlist.Owner.ID,
+				OwnerName:     playlist.Owner.DisplayName,
+				Public:        playlist.Public,
+				Collaborative: playlist.Collaborative,
+			})
+		}
+		url = playlists.Next
 	}
 	return convertedPlaylists
 }
 
+// GetPlaylist will get the details of a single playlist
+func GetPlaylist(playlistID string) *Playlist {
+	fields := "fields=id,name,uri,snapshot_id,tracks.total,description,public,collaborative,owner(id,display_name)"
+	url := fmt.Sprintf("https://api.spotify.com/v1/playlists/%s?%s", playlistID, fields)
+	res := &newPlaylistReq{}
+	err := tryMakeReq("GET", url, res)
+	if !handleError(err) {
+		return nil
+	}
+
+	return &Playlist{
+		ID:            res.ID,
+		Name:          res.Name,
+		URI:           res.URI,
+		SnapshotID:    res.SnapshotID,
+		TrackCount:    res.Tracks.Total,
+		Description:   res.Description,
+		OwnerID:       res.Owner.ID,
+		OwnerName:     res.Owner.DisplayName,
+		Public:        res.Public,
+		Collaborative: res.Collaborative,
+	}
+}
+
 // SetShuffle will set the shuffle status on the device (true = shuffled, false = not shuffled)
 func SetShuffle(shouldShuffle bool) {
 	shouldShuffleString := "false"
@@ -110,6 +180,9 @@ func SetShuffle(shouldShuffle bool) {
 // GetCurrentSong will get the currently playing song or nil if there is no song playing
 func GetCurrentSong() *Song {
 	currentlyPlaying := getCurrentlyPlaying()
+	if currentlyPlaying == nil {
+		return nil
+	}
 
 	if currentlyPlaying.CurrentlyPlayingType != "track" {
 		fmt.Println("No track playing")
@@ -117,23 +190,56 @@ func GetCurrentSong() *Song {
 	}
 
 	artist := ""
+	artistID := ""
 	if currentlyPlaying.Item.Artists != nil && len(currentlyPlaying.Item.Artists) > 0 {
 		artist = currentlyPlaying.Item.Artists[0].Name
+		artistID = currentlyPlaying.Item.Artists[0].ID
 	}
 
 	album := currentlyPlaying.Item.Album.Name
 
 	return &Song{
-		Name:          currentlyPlaying.Item.Name,
-		URI:           currentlyPlaying.Item.URI,
-		PrimaryArtist: artist,
-		Album:         album,
+		Name:            currentlyPlaying.Item.Name,
+		URI:             currentlyPlaying.Item.URI,
+		PrimaryArtist:   artist,
+		PrimaryArtistID: artistID,
+		Album:           album,
+	}
+}
+
+// GetPlaybackState will get what is currently playing
+//
+// Failures aren't printed as it is polled in the background, the returned error says what went wrong
+func GetPlaybackState() (*PlaybackState, error) {
+	currentlyPlaying := &currentlyPlayingRes{}
+	errRes := tryMakeQuietReq("GET", "https://api.spotify.com/v1/me/player", currentlyPlaying)
+	if errRes != nil {
+		return nil, fmt.Errorf("Could not get playback state: %s", errRes.Error.Message)
+	}
+
+	state := &PlaybackState{
+		TrackURI:   currentlyPlaying.Item.URI,
+		TrackName:  currentlyPlaying.Item.Name,
+		AlbumName:  currentlyPlaying.Item.Album.Name,
+		ContextURI: currentlyPlaying.Context.URI,
+		DeviceName: currentlyPlaying.Device.Name,
+		IsPlaying:  currentlyPlaying.IsPlaying,
+		ProgressMs: currentlyPlaying.ProgressMs,
+		DurationMs: currentlyPlaying.Item.DurationMs,
+	}
+	if len(currentlyPlaying.Item.Artists) > 0 {
+		state.ArtistID = currentlyPlaying.Item.Artists[0].ID
+		state.ArtistName = currentlyPlaying.Item.Artists[0].Name
 	}
+	return state, nil
 }
 
 // GetCurrentPlaylist returns the ID from the current playlist
 func GetCurrentPlaylist() *Playlist {
 	currentlyPlaying := getCurrentlyPlaying()
+	if currentlyPlaying == nil {
+		return nil
+	}
 
 	if currentlyPlaying.Context.Type != "playlist" {
 		fmt.Println("Could not get current playlist")
@@ -146,8 +252,10 @@ func GetCurrentPlaylist() *Playlist {
 }
 
 // AddToPlaylist will attempt to add the given song to the playlist
+//
+// The song is added even if it's already there, use FindTrackInPlaylist to check first
 func AddToPlaylist(playlistID string, songURI string) {
-	// TODO need to check if item already exists
+	position := getPlaylistTrackTotal(playlistID)
 
 	url := fmt.Sprintf("https://api.spotify.com/v1/playlists/%s/tracks?uris=%s", playlistID, songURI)
 	err := tryMakeReq("POST", url, nil)
@@ -155,10 +263,31 @@ func AddToPlaylist(playlistID string, songURI string) {
 		return
 	}
 
+	if position >= 0 {
+		recordChange(&JournalEntry{Action: "add", PlaylistID: playlistID, URIs: []string{songURI}, Positions: []int{position}})
+	}
 }
 
 // AddManyToPlaylist will attempt to add the given songs to the playlist with no limit
-func AddManyToPlaylist(playlistID string, songURIs []string) {
+//
+// If a batch fails the songs added before it are kept and recorded in the journal
+func AddManyToPlaylist(playlistID string, songURIs []string) error {
+	position := getPlaylistTrackTotal(playlistID)
+
+	added, err := addManyToPlaylist(playlistID, songURIs)
+
+	if position >= 0 && added > 0 {
+		positions := make([]int, added)
+		for i := range positions {
+			positions[i] = position + i
+		}
+		recordChange(&JournalEntry{Action: "add", PlaylistID: playlistID, URIs: songURIs[:added], Positions: positions})
+	}
+	return err
+}
+
+// addManyToPlaylist adds the songs in batches, stopping at the first batch that fails, and returns how many were added
+func addManyToPlaylist(playlistID string, songURIs []string) (int, error) {
 	limit := 100
 
 	offset := 0
@@ -167,15 +296,19 @@ func AddManyToPlaylist(playlistID string, songURIs []string) {
 		if max > len(songURIs) {
 			max = len(songURIs)
 		}
-		AddManyToPlaylistWithLimit(playlistID, songURIs[offset:max])
+		err := AddManyToPlaylistWithLimit(playlistID, songURIs[offset:max])
+		if err != nil {
+			return offset, err
+		}
 		offset = offset + limit
 	}
+	return len(songURIs), nil
 }
 
 // AddManyToPlaylistWithLimit will attempt to add the given songs to the playlist
 //
 // Limit 100
-func AddManyToPlaylistWithLimit(playlistID string, songURIs []string) {
+func AddManyToPlaylistWithLimit(playlistID string, songURIs []string) error {
 	body := map[string][]string{
 		"uris": songURIs,
 	}
@@ -183,25 +316,196 @@ func AddManyToPlaylistWithLimit(playlistID string, songURIs []string) {
 	url := fmt.Sprintf("https://api.spotify.com/v1/playlists/%s/tracks", playlistID)
 	err := tryMakeReq2("POST", url, nil, body)
 	if !handleError(err) {
-		return
+		return errors.New("Could not add tracks to playlist")
 	}
+	return nil
+}
+
+// insertIntoPlaylist will add the given songs to the playlist starting at the given position
+//
+// Limit 100
+func insertIntoPlaylist(playlistID string, songURIs []string, position int) error {
+	body := &insertPlaylistBody{
+		URIs:     songURIs,
+		Position: position,
+	}
+
+	url := fmt.Sprintf("https://api.spotify.com/v1/playlists/%s/tracks", playlistID)
+	err := tryMakeReq2("POST", url, nil, body)
+	if !handleError(err) {
+		return errors.New("Could not add tracks to playlist")
+	}
+	return nil
 }
 
 // RemoveFromPlaylist will attempt to remove the given song from the given playlist
+//
+// If positions is nil every occurrence of the song is removed
 func RemoveFromPlaylist(playlistID string, songURI string, positions []int) {
+	if positions == nil {
+		positions = findPositions(playlistID, songURI)
+	}
+	if positions != nil && len(positions) == 0 {
+		return
+	}
+
+	tracks := []deletePlaylistBody{deletePlaylistBody{
+		URI:       songURI,
+		Positions: positions,
+	}}
+	if removeTracks(playlistID, tracks) != nil {
+		return
+	}
+
+	if positions != nil {
+		uris := make([]string, len(positions))
+		for i := range uris {
+			uris[i] = songURI
+		}
+		recordChange(&JournalEntry{Action: "remove", PlaylistID: playlistID, URIs: uris, Positions: positions})
+	} else {
+		forgetChanges(playlistID)
+	}
+}
+
+// RemoveManyFromPlaylist will attempt to remove the songs at the given positions with no limit
+//
+// Each URI is removed from the position with the same index
+func RemoveManyFromPlaylist(playlistID string, songURIs []string, positions []int) error {
+	err := removeAtPositions(playlistID, songURIs, positions)
+	if err != nil {
+		return err
+	}
+
+	recordChange(&JournalEntry{Action: "remove", PlaylistID: playlistID, URIs: songURIs, Positions: positions})
+	return nil
+}
+
+// MoveToPlaylist will add the song to the end of one playlist and remove every copy of it from another
+//
+// If the song can't be removed the add is rolled back so the song is only ever in one of the playlists
+func MoveToPlaylist(fromPlaylistID string, toPlaylistID string, songURI string) error {
+	positions := findPositions(fromPlaylistID, songURI)
+	if len(positions) == 0 {
+		return errors.New("The song is not in the current playlist")
+	}
+	position := getPlaylistTrackTotal(toPlaylistID)
+	if position < 0 {
+		return errors.New("Could not get the playlist to move to")
+	}
+
+	url := fmt.Sprintf("https://api.spotify.com/v1/playlists/%s/tracks?uris=%s", toPlaylistID, songURI)
+	addErr := tryMakeReq("POST", url, nil)
+	if !handleError(addErr) {
+		return errors.New("Could not add the song")
+	}
+
+	uris := make([]string, len(positions))
+	for i := range uris {
+		uris[i] = songURI
+	}
+	err := removeAtPositions(fromPlaylistID, uris, positions)
+	if err != nil {
+		rollbackErr := removeAtPositions(toPlaylistID, []string{songURI}, []int{position})
+		if rollbackErr != nil {
+			return fmt.Errorf("Could not remove the song (%s) or take it back out of the playlist it was added to (%s)", err.Error(), rollbackErr.Error())
+		}
+		return fmt.Errorf("Could not remove the song, it was taken back out of the playlist it was added to (%s)", err.Error())
+	}
+
+	recordChange(&JournalEntry{Action: "add", PlaylistID: toPlaylistID, URIs: []string{songURI}, Positions: []int{position}})
+	recordChange(&JournalEntry{Action: "remove", PlaylistID: fromPlaylistID, URIs: uris, Positions: positions})
+	return nil
+}
+
+// removeTracks will remove the given tracks from the playlist
+//
+// Limit 100
+func removeTracks(playlistID string, tracks []deletePlaylistBody) error {
 	body := map[string][]deletePlaylistBody{
-		"name": []deletePlaylistBody{deletePlaylistBody{
-			URI:       songURI,
-			Positions: positions,
-		}},
+		"tracks": tracks,
 	}
 
-	url := fmt.Sprintf("https://api.spotify.com/v1/playlists/%s/tracks?uris=%s", playlistID, songURI)
+	url := fmt.Sprintf("https://api.spotify.com/v1/playlists/%s/tracks", playlistID)
 	err := tryMakeReq2("DELETE", url, nil, body)
 	if !handleError(err) {
-		return
+		return errors.New("Could not remove tracks from playlist")
+	}
+	return nil
+}
+
+// findPositions returns every position of the song in the playlist or nil if the tracks could not be fetched
+func findPositions(playlistID string, songURI string) []int {
+	items := GetTracksInPlaylist(playlistID)
+	if items == nil {
+		return nil
+	}
+
+	positions := make([]int, 0)
+	for i, item := range items {
+		if item != nil && item.Track.URI == songURI {
+			positions = append(positions, i)
+		}
+	}
+	return positions
+}
+
+// getPlaylistTrackTotal returns the number of tracks in the playlist or -1 if it could not be fetched
+func getPlaylistTrackTotal(playlistID string) int {
+	playlist := GetPlaylist(playlistID)
+	if playlist == nil {
+		return -1
+	}
+	return playlist.TrackCount
+}
+
+// ReplacePlaylistTracks will replace every track in the playlist with the given songs, keeping their order
+//
+// The earlier changes to the playlist can't be undone afterwards as their positions no longer line up
+func ReplacePlaylistTracks(playlistID string, songURIs []string) error {
+	forgetChanges(playlistID)
+
+	limit := 100
+	if limit > len(songURIs) {
+		limit = len(songURIs)
 	}
 
+	body := map[string][]string{
+		"uris": songURIs[0:limit],
+	}
+
+	url := fmt.Sprintf("https://api.spotify.com/v1/playlists/%s/tracks", playlistID)
+	err := tryMakeReq2("PUT", url, nil, body)
+	if !handleError(err) {
+		return errors.New("Could not replace playlist tracks")
+	}
+
+	_, addErr := addManyToPlaylist(playlistID, songURIs[limit:])
+	return addErr
+}
+
+// ReorderPlaylistTracks will move rangeLength tracks starting at rangeStart to before insertBefore
+//
+// The earlier changes to the playlist can't be undone afterwards as their positions no longer line up
+//
+// Returns the new snapshot ID of the playlist, snapshotID can be left empty to use the latest snapshot
+func ReorderPlaylistTracks(playlistID string, rangeStart int, insertBefore int, rangeLength int, snapshotID string) (string, error) {
+	body := &reorderPlaylistBody{
+		RangeStart:   rangeStart,
+		InsertBefore: insertBefore,
+		RangeLength:  rangeLength,
+		SnapshotID:   snapshotID,
+	}
+
+	res := &snapshotRes{}
+
+	url := fmt.Sprintf("https://api.spotify.com/v1/playlists/%s/tracks", playlistID)
+	err := tryMakeReq2("PUT", url, res, body)
+	if !handleError(err) {
+		return "", errors.New("Could not reorder playlist")
+	}
+	forgetChanges(playlistID)
+	return res.SnapshotID, nil
 }
 
 // CreateNewPlaylist will create the given playlist
@@ -222,6 +526,28 @@ func CreateNewPlaylist(userID, playlistName string, isPublic bool) (string, erro
 	return res.ID, nil
 }
 
+// ChangePlaylistDetails will change the name, description, public or collaborative flags of the playlist
+//
+// Only the details that are set are changed, a collaborative playlist can't be public
+func ChangePlaylistDetails(playlistID string, details *PlaylistDetails) error {
+	url := fmt.Sprintf("https://api.spotify.com/v1/playlists/%s", playlistID)
+	err := tryMakeReq2("PUT", url, nil, details)
+	if !handleError(err) {
+		return errors.New("Could not change playlist details")
+	}
+	return nil
+}
+
+// UnfollowPlaylist will remove the playlist from the users library, this is how playlists are deleted
+func UnfollowPlaylist(playlistID string) error {
+	url := fmt.Sprintf("https://api.spotify.com/v1/playlists/%s/followers", playlistID)
+	err := tryMakeReq("DELETE", url, nil)
+	if !handleError(err) {
+		return errors.New("Could not delete playlist")
+	}
+	return nil
+}
+
 // GetUserID gets the user ID for the currently logged in user
 func GetUserID() (string, error) {
 	userDetails := getUserDetails()
@@ -247,9 +573,11 @@ func SetVolume(percent int) {
 	makeAuthReq("PUT", "https://api.spotify.com/v1/me/player/volume?volume_percent="+percentStr, nil)
 }
 
-// GetTracksInPlaylist gets all the tracks in a playlist
+// GetTracksInPlaylist gets all the tracks in a playlist or nil if any page could not be fetched
+//
+// An item can still be nil if tracks were removed while the pages were being fetched
 func GetTracksInPlaylist(playlistID string) []*PlaylistTrackResItem {
-	fields := "fields=items(track(name,href,id,uri)),total,limit"
+	fields := "fields=items(added_at,added_by.id,is_local,track(name,href,id,uri,duration_ms,popularity,explicit,external_ids,artists(id,name,uri),album(id,name,uri,release_date,images))),total,limit"
 	url := fmt.Sprintf("https://api.spotify.com/v1/playlists/%s/tracks?market=NZ&%s&limit=100", playlistID, fields)
 	res := &playlistTrackRes{}
 
@@ -267,8 +595,11 @@ func GetTracksInPlaylist(playlistID string) []*PlaylistTrackResItem {
 	return items
 }
 
+// getPagesAsync fetches every page at once, it returns nil if any page fails so a partial list is never used
 func getPagesAsync(url string, paging *playlistTrackRes, res interface{}) []*PlaylistTrackResItem {
 	var wg sync.WaitGroup
+	var failedMutex sync.Mutex
+	failed := false
 
 	pageTotal := paging.Total/paging.Limit + 1
 	resTotal := make([]*PlaylistTrackResItem, paging.Total)
@@ -281,13 +612,25 @@ func getPagesAsync(url string, paging *playlistTrackRes, res interface{}) []*Pla
 		go func() {
 			defer wg.Done()
 			res := getSinglePage(url, offset)
+			if res == nil {
+				failedMutex.Lock()
+				failed = true
+				failedMutex.Unlock()
+				return
+			}
+			// Tracks added since the first page was fetched are left out
 			for resIndex := range res.Items {
-				resTotal[resIndex+offset] = &res.Items[resIndex]
+				if resIndex+offset < len(resTotal) {
+					resTotal[resIndex+offset] = &res.Items[resIndex]
+				}
 			}
 		}()
 		i++
 	}
 	wg.Wait()
+	if failed {
+		return nil
+	}
 	return resTotal
 }
 
@@ -335,9 +678,11 @@ func getIDFromURI(URI string) string {
 	return splitURI[len(splitURI)-1]
 }
 
+// getCurrentlyPlaying returns nil if spotify couldn't be reached or the request failed
 func getCurrentlyPlaying() *currentlyPlayingRes {
 	currentlyPlaying := &currentlyPlayingRes{}
-	err := tryMakeReq("GET", "https://api.spotify.com/v1/me/player/currently-playing", currentlyPlaying)
+	// The player endpoint returns the same as currently-playing along with the device
+	err := tryMakeReq("GET", "https://api.spotify.com/v1/me/player", currentlyPlaying)
 	if !handleError(err) {
 		return nil
 	}
@@ -351,11 +696,13 @@ type playlistReq struct {
 		ExternalUrls  struct {
 			Spotify string `json:"spotify"`
 		} `json:"external_urls"`
-		Href   string        `json:"href"`
-		ID     string        `json:"id"`
-		Images []interface{} `json:"images"`
-		Name   string        `json:"name"`
-		Owner  struct {
+		Description string        `json:"description"`
+		Href        string        `json:"href"`
+		ID          string        `json:"id"`
+		Images      []interface{} `json:"images"`
+		Name        string        `json:"name"`
+		Owner       struct {
+			DisplayName  string `json:"display_name"`
 			ExternalUrls struct {
 				Spotify string `json:"spotify"`
 			} `json:"external_urls"`
@@ -374,7 +721,7 @@ type playlistReq struct {
 		URI  string `json:"uri"`
 	} `json:"items"`
 	Limit    int         `json:"limit"`
-	Next     interface{} `json:"next"`
+	Next     string      `json:"next"`
 	Offset   int         `json:"offset"`
 	Previous interface{} `json:"previous"`
 	Total    int         `json:"total"`
@@ -382,17 +729,48 @@ type playlistReq struct {
 
 // Song represents a spotify song
 type Song struct {
-	Name          string
-	URI           string
-	PrimaryArtist string
-	Album         string
+	Name            string
+	URI             string
+	PrimaryArtist   string
+	PrimaryArtistID string
+	Album           string
+}
+
+// PlaybackState is what is currently playing
+type PlaybackState struct {
+	TrackURI   string
+	TrackName  string
+	ArtistID   string
+	ArtistName string
+	AlbumName  string
+	// ContextURI is the playlist, album or artist being played, empty when playing a list of tracks
+	ContextURI string
+	DeviceName string
+	IsPlaying  bool
+	ProgressMs int
+	DurationMs int
 }
 
 // Playlist represents a spotify playlist
 type Playlist struct {
-	Name string
-	ID   string
-	URI  string
+	Name          string
+	ID            string
+	URI           string
+	SnapshotID    string
+	TrackCount    int
+	Description   string
+	OwnerID       string
+	OwnerName     string
+	Public        bool
+	Collaborative bool
+}
+
+// PlaylistDetails are the details of a playlist that can be changed, nil details are left as they are
+type PlaylistDetails struct {
+	Name          *string `json:"name,omitempty"`
+	Description   *string `json:"description,omitempty"`
+	Public        *bool   `json:"public,omitempty"`
+	Collaborative *bool   `json:"collaborative,omitempty"`
 }
 
 type deletePlaylistBody struct {
@@ -400,12 +778,33 @@ type deletePlaylistBody struct {
 	Positions []int  `json:"positions"`
 }
 
+type insertPlaylistBody struct {
+	URIs     []string `json:"uris"`
+	Position int      `json:"position"`
+}
+
+type reorderPlaylistBody struct {
+	RangeStart   int    `json:"range_start"`
+	InsertBefore int    `json:"insert_before"`
+	RangeLength  int    `json:"range_length"`
+	SnapshotID   string `json:"snapshot_id,omitempty"`
+}
+
+type snapshotRes struct {
+	SnapshotID string `json:"snapshot_id"`
+}
+
 type createPlaylistBody struct {
 	Name   string `json:"name"`
 	Public bool   `json:"public"`
 }
 
 type currentlyPlayingRes struct {
+	Device struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+		Type string `json:"type"`
+	} `json:"device"`
 	Context struct {
 		ExternalUrls struct {
 			Spotify string `json:"spotify"`
@@ -498,36 +897,15 @@ type PlaylistTrackResItem struct {
 		Type string `json:"type"`
 		URI  string `json:"uri"`
 	} `json:"added_by"`
-	IsLocal bool `json:"is_local"`
-	Track   struct {
-		Album struct {
-			AlbumType string `json:"album_type"`
-			Artists   []struct {
-				ExternalUrls struct {
-					Spotify string `json:"spotify"`
-				} `json:"external_urls"`
-				Href string `json:"href"`
-				ID   string `json:"id"`
-				Name string `json:"name"`
-				Type string `json:"type"`
-				URI  string `json:"uri"`
-			} `json:"artists"`
-			AvailableMarkets []string `json:"available_markets"`
-			ExternalUrls     struct {
-				Spotify string `json:"spotify"`
-			} `json:"external_urls"`
-			Href   string `json:"href"`
-			ID     string `json:"id"`
-			Images []struct {
-				Height int    `json:"height"`
-				URL    string `json:"url"`
-				Width  int    `json:"width"`
-			} `json:"images"`
-			Name string `json:"name"`
-			Type string `json:"type"`
-			URI  string `json:"uri"`
-		} `json:"album"`
-		Artists []struct {
+	IsLocal bool  `json:"is_local"`
+	Track   Track `json:"track,omitempty"`
+}
+
+// Track represents a spotify track
+type Track struct {
+	Album struct {
+		AlbumType string `json:"album_type"`
+		Artists   []struct {
 			ExternalUrls struct {
 				Spotify string `json:"spotify"`
 			} `json:"external_urls"`
@@ -538,24 +916,49 @@ type PlaylistTrackResItem struct {
 			URI  string `json:"uri"`
 		} `json:"artists"`
 		AvailableMarkets []string `json:"available_markets"`
-		DiscNumber       int      `json:"disc_number"`
-		DurationMs       int      `json:"duration_ms"`
-		Explicit         bool     `json:"explicit"`
-		ExternalIds      struct {
-			Isrc string `json:"isrc"`
-		} `json:"external_ids"`
-		ExternalUrls struct {
+		ExternalUrls     struct {
 			Spotify string `json:"spotify"`
 		} `json:"external_urls"`
-		Href        string `json:"href"`
-		ID          string `json:"id"`
+		Href   string `json:"href"`
+		ID     string `json:"id"`
+		Images []struct {
+			Height int    `json:"height"`
+			URL    string `json:"url"`
+			Width  int    `json:"width"`
+		} `json:"images"`
 		Name        string `json:"name"`
-		Popularity  int    `json:"popularity"`
-		PreviewURL  string `json:"preview_url"`
-		TrackNumber int    `json:"track_number"`
+		ReleaseDate string `json:"release_date"`
 		Type        string `json:"type"`
 		URI         string `json:"uri"`
-	} `json:"track,omitempty"`
+	} `json:"album"`
+	Artists []struct {
+		ExternalUrls struct {
+			Spotify string `json:"spotify"`
+		} `json:"external_urls"`
+		Href string `json:"href"`
+		ID   string `json:"id"`
+		Name string `json:"name"`
+		Type string `json:"type"`
+		URI  string `json:"uri"`
+	} `json:"artists"`
+	AvailableMarkets []string `json:"available_markets"`
+	DiscNumber       int      `json:"disc_number"`
+	DurationMs       int      `json:"duration_ms"`
+	Explicit         bool     `json:"explicit"`
+	ExternalIds      struct {
+		Isrc string `json:"isrc"`
+	} `json:"external_ids"`
+	ExternalUrls struct {
+		Spotify string `json:"spotify"`
+	} `json:"external_urls"`
+	Href        string `json:"href"`
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Popularity  int    `json:"popularity"`
+	PreviewURL  string `json:"preview_url"`
+	TrackNumber int    `json:"track_number"`
+	Type        string `json:"type"`
+	URI         string `json:"uri"`
 }
 
 type userDetailReq struct {
@@ -582,8 +985,8 @@ type userDetailReq struct {
 }
 
 type newPlaylistReq struct {
-	Collaborative bool        `json:"collaborative"`
-	Description   interface{} `json:"description"`
+	Collaborative bool   `json:"collaborative"`
+	Description   string `json:"description"`
 	ExternalUrls  struct {
 		Spotify string `json:"spotify"`
 	} `json:"external_urls"`
@@ -596,6 +999,7 @@ type newPlaylistReq struct {
 	Images []interface{} `json:"images"`
 	Name   string        `json:"name"`
 	Owner  struct {
+		DisplayName  string `json:"display_name"`
 		ExternalUrls struct {
 			Spotify string `json:"spotify"`
 		} `json:"external_urls"`
@@ -619,6 +1023,11 @@ type newPlaylistReq struct {
 	URI  string `json:"uri"`
 }
 
+type queueRes struct {
+	CurrentlyPlaying *Track  `json:"currently_playing"`
+	Queue            []Track `json:"queue"`
+}
+
 type playReq struct {
 	URIs []string `json:"uris"`
 }
@@ -0,0 +1,121 @@
+package tracklist
+
+import "sort"
+
+// Diff is the difference between two track lists
+type Diff struct {
+	Added   []*Entry
+	Removed []*Entry
+	Moved   []*Move
+	// Unchanged is the number of tracks in both lists that kept their order
+	Unchanged int
+}
+
+// Move is a track that is in both lists but changed position
+type Move struct {
+	Entry *Entry
+	// From and To are positions starting at 0
+	From int
+	To   int
+}
+
+// Compare finds the tracks added, removed and moved going from before to after
+//
+// Tracks are matched by spotify ID first then by ISRC. A track counts as moved when it is
+// not part of the longest run of matched tracks that kept their relative order
+func Compare(before []*Entry, after []*Entry) *Diff {
+	diff := &Diff{}
+
+	oldByID := make(map[string][]int)
+	oldByISRC := make(map[string][]int)
+	for i, entry := range before {
+		if id := entry.TrackID(); id != "" {
+			oldByID[id] = append(oldByID[id], i)
+		}
+		if entry.ISRC != "" {
+			oldByISRC[entry.ISRC] = append(oldByISRC[entry.ISRC], i)
+		}
+	}
+
+	matched := make([]bool, len(before))
+	take := func(positions []int) int {
+		for _, position := range positions {
+			if !matched[position] {
+				matched[position] = true
+				return position
+			}
+		}
+		return -1
+	}
+
+	// oldPositions holds the position in before of each entry in after, or -1 if it was added
+	oldPositions := make([]int, len(after))
+	for i, entry := range after {
+		oldPositions[i] = -1
+		if id := entry.TrackID(); id != "" {
+			oldPositions[i] = take(oldByID[id])
+		}
+	}
+	// ISRCs are only used once the IDs are matched so an ID match is never taken by an ISRC
+	for i, entry := range after {
+		if oldPositions[i] < 0 && entry.ISRC != "" {
+			oldPositions[i] = take(oldByISRC[entry.ISRC])
+		}
+		if oldPositions[i] < 0 {
+			diff.Added = append(diff.Added, entry)
+		}
+	}
+
+	for i, entry := range before {
+		if !matched[i] {
+			diff.Removed = append(diff.Removed, entry)
+		}
+	}
+
+	kept := longestIncreasing(oldPositions)
+	for i, entry := range after {
+		if oldPositions[i] < 0 {
+			continue
+		}
+		if kept[i] {
+			diff.Unchanged++
+			continue
+		}
+		diff.Moved = append(diff.Moved, &Move{Entry: entry, From: oldPositions[i], To: i})
+	}
+
+	return diff
+}
+
+// longestIncreasing marks the indexes that make up the longest increasing subsequence of
+// the non negative values
+func longestIncreasing(values []int) map[int]bool {
+	// tails[k] is the index of the smallest value ending an increasing run of length k+1
+	tails := make([]int, 0)
+	previous := make([]int, len(values))
+	for i, value := range values {
+		previous[i] = -1
+		if value < 0 {
+			continue
+		}
+
+		k := sort.Search(len(tails), func(k int) bool { return values[tails[k]] >= value })
+		if k > 0 {
+			previous[i] = tails[k-1]
+		}
+		if k == len(tails) {
+			tails = append(tails, i)
+		} else {
+			tails[k] = i
+		}
+	}
+
+	kept := make(map[int]bool)
+	if len(tails) == 0 {
+		return kept
+	}
+	for i := tails[len(tails)-1]; i >= 0; i = previous[i] {
+		kept[i] = true
+	}
+	return kept
+}
@@ -0,0 +1,163 @@
+package tracklist
+
+import "testing"
+
+// uris builds entries with spotify URIs from the given IDs
+func uris(ids ...string) []*Entry {
+	entries := make([]*Entry, len(ids))
+	for i, id := range ids {
+		entries[i] = &Entry{URI: "spotify:track:" + id}
+	}
+	return entries
+}
+
+func ids(entries []*Entry) []string {
+	result := make([]string, len(entries))
+	for i, entry := range entries {
+		result[i] = entry.TrackID()
+		if result[i] == "" {
+			result[i] = entry.ISRC
+		}
+	}
+	return result
+}
+
+func sameStrings(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name          string
+		before        []*Entry
+		after         []*Entry
+		wantAdded     []string
+		wantRemoved   []string
+		wantMoved     []string
+		wantUnchanged int
+	}{
+		{"same", uris("a", "b", "c"), uris("a", "b", "c"), []string{}, []string{}, []string{}, 3},
+		{"both empty", nil, nil, []string{}, []string{}, []string{}, 0},
+		{"added and removed", uris("a", "b", "c"), uris("a", "c", "d"), []string{"d"}, []string{"b"}, []string{}, 2},
+		{"one track moved to the end", uris("a", "b", "c", "d"), uris("b", "c", "d", "a"), []string{}, []string{}, []string{"a"}, 3},
+		{"one track moved to the start", uris("a", "b", "c", "d"), uris("d", "a", "b", "c"), []string{}, []string{}, []string{"d"}, 3},
+		{"reversed", uris("a", "b", "c"), uris("c", "b", "a"), []string{}, []string{}, []string{"c", "b"}, 1},
+		{"everything replaced", uris("a", "b"), uris("c", "d"), []string{"c", "d"}, []string{"a", "b"}, []string{}, 0},
+		{"duplicate kept once", uris("a", "a", "b"), uris("a", "b"), []string{}, []string{"a"}, []string{}, 2},
+		{"duplicate added", uris("a", "b"), uris("a", "b", "a"), []string{"a"}, []string{}, []string{}, 2},
+	}
+
+	for _, test := range tests {
+		diff := Compare(test.before, test.after)
+		moved := make([]string, len(diff.Moved))
+		for i, move := range diff.Moved {
+			moved[i] = move.Entry.TrackID()
+		}
+
+		if !sameStrings(ids(diff.Added), test.wantAdded) {
+			t.Errorf("%s: added %v, want %v", test.name, ids(diff.Added), test.wantAdded)
+		}
+		if !sameStrings(ids(diff.Removed), test.wantRemoved) {
+			t.Errorf("%s: removed %v, want %v", test.name, ids(diff.Removed), test.wantRemoved)
+		}
+		if !sameStrings(moved, test.wantMoved) {
+			t.Errorf("%s: moved %v, want %v", test.name, moved, test.wantMoved)
+		}
+		if diff.Unchanged != test.wantUnchanged {
+			t.Errorf("%s: %d unchanged, want %d", test.name, diff.Unchanged, test.wantUnchanged)
+		}
+	}
+}
+
+func TestCompareMovePositions(t *testing.T) {
+	diff := Compare(uris("a", "b", "c", "d"), uris("b", "c", "a", "d"))
+	if len(diff.Moved) != 1 {
+		t.Fatalf("got %d moves, want 1", len(diff.Moved))
+	}
+	if move := diff.Moved[0]; move.Entry.TrackID() != "a" || move.From != 0 || move.To != 2 {
+		t.Errorf("got move of %s from %d to %d, want a from 0 to 2", move.Entry.TrackID(), move.From, move.To)
+	}
+}
+
+func TestCompareISRC(t *testing.T) {
+	tests := []struct {
+		name          string
+		before        []*Entry
+		after         []*Entry
+		wantAdded     int
+		wantRemoved   int
+		wantUnchanged int
+	}{
+		{
+			name:          "relinked track matched by ISRC",
+			before:        []*Entry{{URI: "spotify:track:old", ISRC: "X1"}},
+			after:         []*Entry{{URI: "spotify:track:new", ISRC: "X1"}},
+			wantUnchanged: 1,
+		},
+		{
+			name:          "file entry without a URI",
+			before:        []*Entry{{URI: "spotify:track:a", ISRC: "X1"}},
+			after:         []*Entry{{ISRC: "X1"}},
+			wantUnchanged: 1,
+		},
+		{
+			name:          "ID match is not taken by an ISRC",
+			before:        []*Entry{{URI: "spotify:track:a", ISRC: "X1"}},
+			after:         []*Entry{{URI: "spotify:track:b", ISRC: "X1"}, {URI: "spotify:track:a", ISRC: "X1"}},
+			wantAdded:     1,
+			wantUnchanged: 1,
+		},
+		{
+			name:          "different ISRCs",
+			before:        []*Entry{{ISRC: "X1"}},
+			after:         []*Entry{{ISRC: "X2"}},
+			wantAdded:     1,
+			wantRemoved:   1,
+			wantUnchanged: 0,
+		},
+	}
+
+	for _, test := range tests {
+		diff := Compare(test.before, test.after)
+		if len(diff.Added) != test.wantAdded || len(diff.Removed) != test.wantRemoved || diff.Unchanged != test.wantUnchanged {
+			t.Errorf("%s: %d added, %d removed, %d unchanged, want %d, %d, %d", test.name,
+				len(diff.Added), len(diff.Removed), diff.Unchanged, test.wantAdded, test.wantRemoved, test.wantUnchanged)
+		}
+	}
+}
+
+func TestLongestIncreasing(t *testing.T) {
+	tests := []struct {
+		values []int
+		want   []int
+	}{
+		{[]int{}, []int{}},
+		{[]int{0, 1, 2}, []int{0, 1, 2}},
+		{[]int{2, 1, 0}, []int{2}},
+		{[]int{1, 2, 3, 0}, []int{0, 1, 2}},
+		{[]int{-1, 0, -1, 1}, []int{1, 3}},
+		{[]int{3, 0, 1, 4, 2}, []int{1, 2, 4}},
+	}
+
+	for _, test := range tests {
+		kept := longestIncreasing(test.values)
+		if len(kept) != len(test.want) {
+			t.Errorf("longestIncreasing(%v) = %v, want indexes %v", test.values, kept, test.want)
+			continue
+		}
+		for _, index := range test.want {
+			if !kept[index] {
+				t.Errorf("longestIncreasing(%v) = %v, want indexes %v", test.values, kept, test.want)
+				break
+			}
+		}
+	}
+}
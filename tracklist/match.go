@@ -0,0 +1,85 @@
+package tracklist
+
+import (
+	"strings"
+	"unicode"
+)
+
+// MinConfidence is the lowest score a search result needs to be accepted as a match
+const MinConfidence = 0.6
+
+// Score returns how confident we are (0 to 1) that a track with the given details is the entry
+//
+// The title counts for most of the score, then the artist, and durations within a few seconds
+// give a small bonus
+func Score(entry *Entry, title string, artists []string, duration int) float64 {
+	titleScore := similarity(entry.Title, title)
+
+	artistScore := 0.0
+	if entry.Artist == "" {
+		artistScore = 0.5
+	}
+	for _, artist := range artists {
+		if score := similarity(entry.Artist, artist); score > artistScore {
+			artistScore = score
+		}
+	}
+
+	score := 0.65*titleScore + 0.35*artistScore
+	if entry.Duration > 0 && duration > 0 {
+		diff := entry.Duration - duration
+		if diff < 0 {
+			diff = -diff
+		}
+		if diff <= 3 {
+			score += 0.05
+		} else if diff > 30 {
+			score -= 0.1
+		}
+	}
+
+	if score > 1 {
+		return 1
+	}
+	if score < 0 {
+		return 0
+	}
+	return score
+}
+
+// similarity compares the words in two strings, ignoring case, punctuation and
+// suffixes such as "(feat. X)" or "- Remastered"
+func similarity(a string, b string) float64 {
+	wordsA := words(a)
+	wordsB := words(b)
+	if len(wordsA) == 0 || len(wordsB) == 0 {
+		return 0
+	}
+
+	shared := 0
+	for word := range wordsA {
+		if wordsB[word] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(wordsA)+len(wordsB)-shared)
+}
+
+func words(text string) map[string]bool {
+	text = strings.ToLower(text)
+	for _, suffix := range []string{" - ", " (feat", " [feat", " feat.", " (with "} {
+		if index := strings.Index(text, suffix); index > 0 {
+			text = text[:index]
+		}
+	}
+
+	fields := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+
+	set := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		set[field] = true
+	}
+	return set
+}
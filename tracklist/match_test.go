@@ -0,0 +1,83 @@
+package tracklist
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		a    string
+		b    string
+		want float64
+	}{
+		{"Hello", "hello", 1},
+		{"Don't Stop Me Now", "Dont Stop Me Now", 0.5},
+		{"Hello - Remastered 2011", "Hello", 1},
+		{"Lean On (feat. MØ)", "Lean On", 1},
+		{"Lean On", "Lean On [feat. MØ]", 1},
+		{"Hello World", "Hello", 0.5},
+		{"Hello", "Goodbye", 0},
+		{"", "Hello", 0},
+		{"!!!", "Hello", 0},
+	}
+
+	for _, test := range tests {
+		if got := similarity(test.a, test.b); math.Abs(got-test.want) > 1e-9 {
+			t.Errorf("similarity(%q, %q) = %v, want %v", test.a, test.b, got, test.want)
+		}
+	}
+}
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		name      string
+		entry     Entry
+		title     string
+		artists   []string
+		duration  int
+		want      float64
+		wantMatch bool
+	}{
+		{"exact", Entry{Title: "Hello", Artist: "Adele", Duration: 295}, "Hello", []string{"Adele"}, 295, 1, true},
+		{"no durations", Entry{Title: "Hello", Artist: "Adele"}, "Hello", []string{"Adele"}, 295, 1, true},
+		{"second artist", Entry{Title: "Hello", Artist: "Adele"}, "Hello", []string{"Someone", "Adele"}, 0, 1, true},
+		{"no artist in entry", Entry{Title: "Hello"}, "Hello", []string{"Adele"}, 0, 0.825, true},
+		{"duration far off", Entry{Title: "Hello", Artist: "Adele", Duration: 100}, "Hello", []string{"Adele"}, 295, 0.9, true},
+		{"wrong artist", Entry{Title: "Hello", Artist: "Lionel Richie", Duration: 295}, "Hello", []string{"Adele"}, 295, 0.7, true},
+		{"wrong title", Entry{Title: "Hello", Artist: "Adele", Duration: 295}, "Skyfall", []string{"Adele"}, 295, 0.4, false},
+		{"nothing in common", Entry{Title: "Hello", Artist: "Adele", Duration: 100}, "Skyfall", []string{"Someone"}, 295, 0, false},
+	}
+
+	for _, test := range tests {
+		got := Score(&test.entry, test.title, test.artists, test.duration)
+		if math.Abs(got-test.want) > 1e-9 {
+			t.Errorf("%s: score = %v, want %v", test.name, got, test.want)
+		}
+		if (got >= MinConfidence) != test.wantMatch {
+			t.Errorf("%s: score %v against minimum %v, want match %v", test.name, got, MinConfidence, test.wantMatch)
+		}
+	}
+}
+
+func TestTrackURI(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"spotify:track:abc", "spotify:track:abc"},
+		{"  spotify:track:abc\t", "spotify:track:abc"},
+		{"https://open.spotify.com/track/abc?si=1", "spotify:track:abc"},
+		{"http://open.spotify.com/track/abc/", "spotify:track:abc"},
+		{"https://open.spotify.com/track/", ""},
+		{"https://open.spotify.com/album/abc", ""},
+		{"spotify:album:abc", ""},
+		{"hello.mp3", ""},
+	}
+
+	for _, test := range tests {
+		if got := TrackURI(test.text); got != test.want {
+			t.Errorf("TrackURI(%q) = %q, want %q", test.text, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,252 @@
+package tracklist
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+)
+
+// csvColumns maps the accepted (lower case) csv headers to the entry field they fill
+var csvColumns = map[string]string{
+	"uri":            "uri",
+	"track uri":      "uri",
+	"spotify uri":    "uri",
+	"url":            "uri",
+	"isrc":           "isrc",
+	"title":          "title",
+	"name":           "title",
+	"track":          "title",
+	"track name":     "title",
+	"artist":         "artist",
+	"artists":        "artist",
+	"artist name":    "artist",
+	"artist name(s)": "artist",
+	"album":          "album",
+	"album name":     "album",
+	"duration":       "duration",
+	"duration_ms":    "duration_ms",
+	"duration (ms)":  "duration_ms",
+}
+
+// ReadCSV will read entries from a csv file with a header row
+func ReadCSV(reader io.Reader) ([]*Entry, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+
+	header, err := csvReader.Read()
+	if err == io.EOF {
+		return nil, errors.New("CSV file is empty")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make([]string, len(header))
+	hasColumn := false
+	for i, name := range header {
+		columns[i] = csvColumns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))]
+		if columns[i] != "" {
+			hasColumn = true
+		}
+	}
+	if !hasColumn {
+		return nil, errors.New("CSV header has no known columns (uri, isrc, title, artist, album, duration)")
+	}
+
+	entries := make([]*Entry, 0)
+	row := 1
+	for {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		row++
+
+		entry := &Entry{Row: row}
+		for i, value := range record {
+			if i >= len(columns) {
+				break
+			}
+			value = strings.TrimSpace(value)
+			switch columns[i] {
+			case "uri":
+				entry.URI = value
+			case "isrc":
+				entry.ISRC = value
+			case "title":
+				entry.Title = value
+			case "artist":
+				entry.Artist = firstArtist(value)
+			case "album":
+				entry.Album = value
+			case "duration":
+				entry.Duration, _ = strconv.Atoi(value)
+			case "duration_ms":
+				ms, _ := strconv.Atoi(value)
+				entry.Duration = ms / 1000
+			}
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// ReadM3U will read entries from an m3u playlist
+//
+// Titles and artists are taken from #EXTINF lines in the form "Artist - Title"
+func ReadM3U(reader io.Reader) ([]*Entry, error) {
+	scanner := bufio.NewScanner(reader)
+
+	entries := make([]*Entry, 0)
+	var info *Entry
+	row := 0
+	for scanner.Scan() {
+		row++
+		line := strings.TrimSpace(scanner.Text())
+		if row == 1 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "#EXTINF:") {
+			info = parseExtInf(line[len("#EXTINF:"):])
+			info.Row = row
+			continue
+		}
+
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		entry := info
+		if entry == nil {
+			entry = &Entry{Row: row}
+		}
+		info = nil
+
+		if uri := TrackURI(line); uri != "" {
+			entry.URI = uri
+		} else if entry.Title == "" {
+			entry.Artist, entry.Title = splitArtistTitle(fileTitle(line))
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+type jsonEntry struct {
+	URI        string `json:"uri"`
+	ISRC       string `json:"isrc"`
+	Title      string `json:"title"`
+	Name       string `json:"name"`
+	Artist     string `json:"artist"`
+	Album      string `json:"album"`
+	Duration   int    `json:"duration"`
+	DurationMs int    `json:"duration_ms"`
+}
+
+// ReadJSON will read entries from either a json array of tracks or an object with a "tracks" array
+func ReadJSON(reader io.Reader) ([]*Entry, error) {
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]jsonEntry, 0)
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		wrapped := struct {
+			Tracks []jsonEntry `json:"tracks"`
+		}{}
+		err = json.Unmarshal(data, &wrapped)
+		items = wrapped.Tracks
+	} else {
+		err = json.Unmarshal(data, &items)
+	}
+	if err != nil {
+		return nil, errors.New("Could not decode json track list")
+	}
+
+	entries := make([]*Entry, len(items))
+	for i, item := range items {
+		entry := &Entry{
+			Row:      i + 1,
+			URI:      item.URI,
+			ISRC:     item.ISRC,
+			Title:    item.Title,
+			Artist:   firstArtist(item.Artist),
+			Album:    item.Album,
+			Duration: item.Duration,
+		}
+		if entry.Title == "" {
+			entry.Title = item.Name
+		}
+		if entry.Duration == 0 {
+			entry.Duration = item.DurationMs / 1000
+		}
+		entries[i] = entry
+	}
+	return entries, nil
+}
+
+// parseExtInf parses the "duration,Artist - Title" part of an #EXTINF line
+func parseExtInf(info string) *Entry {
+	entry := &Entry{}
+	comma := strings.Index(info, ",")
+	if comma < 0 {
+		return entry
+	}
+
+	duration, err := strconv.Atoi(strings.TrimSpace(info[:comma]))
+	if err == nil && duration > 0 {
+		entry.Duration = duration
+	}
+	entry.Artist, entry.Title = splitArtistTitle(info[comma+1:])
+	return entry
+}
+
+func splitArtistTitle(text string) (string, string) {
+	parts := strings.SplitN(text, " - ", 2)
+	if len(parts) == 1 {
+		return "", strings.TrimSpace(parts[0])
+	}
+	return strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+}
+
+// fileTitle returns the file name of a path without its directory or extension
+func fileTitle(path string) string {
+	path = strings.Replace(path, "\\", "/", -1)
+	if slash := strings.LastIndex(path, "/"); slash >= 0 {
+		path = path[slash+1:]
+	}
+	if dot := strings.LastIndex(path, "."); dot > 0 {
+		path = path[:dot]
+	}
+	return path
+}
+
+// firstArtist returns the first artist when several are listed
+func firstArtist(artists string) string {
+	for _, separator := range []string{";", ","} {
+		if index := strings.Index(artists, separator); index >= 0 {
+			return strings.TrimSpace(artists[:index])
+		}
+	}
+	return artists
+}
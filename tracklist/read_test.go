@@ -0,0 +1,154 @@
+package tracklist
+
+import (
+	"strings"
+	"testing"
+)
+
+func checkEntries(t *testing.T, name string, got []*Entry, want []Entry) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Errorf("%s: got %d entries, want %d", name, len(got), len(want))
+		return
+	}
+	for i := range want {
+		if *got[i] != want[i] {
+			t.Errorf("%s: entry %d = %+v, want %+v", name, i, *got[i], want[i])
+		}
+	}
+}
+
+func TestReadCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []Entry
+		wantErr bool
+	}{
+		{
+			name:  "uri and title",
+			input: "uri,title\nspotify:track:1,Hello\n",
+			want:  []Entry{{Row: 2, URI: "spotify:track:1", Title: "Hello"}},
+		},
+		{
+			name:  "exported headers in any case",
+			input: "Track Name,Artist Name(s),Album Name,Duration (ms),ISRC\nHello,\"Adele, Someone\",25,295000,GBBKS1500214\n",
+			want:  []Entry{{Row: 2, Title: "Hello", Artist: "Adele", Album: "25", Duration: 295, ISRC: "GBBKS1500214"}},
+		},
+		{
+			name:  "byte order mark and padding",
+			input: "\ufeffname , artist\n  Yesterday  ,The Beatles;Someone\n",
+			want:  []Entry{{Row: 2, Title: "Yesterday", Artist: "The Beatles"}},
+		},
+		{
+			name:  "unknown columns and short rows",
+			input: "notes,title,duration\nignored,One,60\nignored\n",
+			want:  []Entry{{Row: 2, Title: "One", Duration: 60}, {Row: 3}},
+		},
+		{
+			name:  "header only",
+			input: "title,artist\n",
+			want:  []Entry{},
+		},
+		{
+			name:    "empty",
+			input:   "",
+			wantErr: true,
+		},
+		{
+			name:    "no known columns",
+			input:   "foo,bar\n1,2\n",
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		entries, err := ReadCSV(strings.NewReader(test.input))
+		if (err != nil) != test.wantErr {
+			t.Errorf("%s: error = %v, want error %v", test.name, err, test.wantErr)
+			continue
+		}
+		if !test.wantErr {
+			checkEntries(t, test.name, entries, test.want)
+		}
+	}
+}
+
+func TestReadM3U(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []Entry
+	}{
+		{
+			name:  "extended info",
+			input: "#EXTM3U\n#EXTINF:295,Adele - Hello\nmusic/adele/hello.mp3\n",
+			want:  []Entry{{Row: 2, Title: "Hello", Artist: "Adele", Duration: 295}},
+		},
+		{
+			name:  "file names only",
+			input: "C:\\Music\\Queen - Bohemian Rhapsody.flac\r\n\r\n/music/Interlude.mp3\n",
+			want:  []Entry{{Row: 1, Title: "Bohemian Rhapsody", Artist: "Queen"}, {Row: 3, Title: "Interlude"}},
+		},
+		{
+			name:  "spotify links",
+			input: "\ufeffhttps://open.spotify.com/track/abc?si=123\n#EXTINF:-1,Someone - Something\nspotify:track:def\n",
+			want:  []Entry{{Row: 1, URI: "spotify:track:abc"}, {Row: 2, URI: "spotify:track:def", Title: "Something", Artist: "Someone"}},
+		},
+		{
+			name:  "info without a dash",
+			input: "#EXTINF:100,Just A Title\nfile.mp3\n",
+			want:  []Entry{{Row: 1, Title: "Just A Title", Duration: 100}},
+		},
+		{
+			name:  "comments only",
+			input: "#EXTM3U\n# a comment\n",
+			want:  []Entry{},
+		},
+	}
+
+	for _, test := range tests {
+		entries, err := ReadM3U(strings.NewReader(test.input))
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", test.name, err)
+			continue
+		}
+		checkEntries(t, test.name, entries, test.want)
+	}
+}
+
+func TestReadJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []Entry
+		wantErr bool
+	}{
+		{
+			name:  "array",
+			input: `[{"uri":"spotify:track:1","title":"One","artist":"A, B","duration":60}]`,
+			want:  []Entry{{Row: 1, URI: "spotify:track:1", Title: "One", Artist: "A", Duration: 60}},
+		},
+		{
+			name:  "tracks object with name and milliseconds",
+			input: ` {"tracks":[{"name":"Two","duration_ms":120500},{"isrc":"X1"}]}`,
+			want:  []Entry{{Row: 1, Title: "Two", Duration: 120}, {Row: 2, ISRC: "X1"}},
+		},
+		{
+			name:    "invalid",
+			input:   `{"tracks":`,
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		entries, err := ReadJSON(strings.NewReader(test.input))
+		if (err != nil) != test.wantErr {
+			t.Errorf("%s: error = %v, want error %v", test.name, err, test.wantErr)
+			continue
+		}
+		if !test.wantErr {
+			checkEntries(t, test.name, entries, test.want)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package tracklist
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Entry is a single track read from a track list file
+type Entry struct {
+	// Row is the line or item number the entry was read from (starting at 1)
+	Row      int
+	URI      string
+	ISRC     string
+	Title    string
+	Artist   string
+	Album    string
+	Duration int
+	// AddedBy and AddedAt are only known for entries read from a playlist
+	AddedBy string
+	AddedAt time.Time
+}
+
+// Label returns a human readable description of the entry
+func (entry *Entry) Label() string {
+	if entry.Title != "" && entry.Artist != "" {
+		return entry.Title + " - " + entry.Artist
+	}
+	if entry.Title != "" {
+		return entry.Title
+	}
+	if entry.ISRC != "" {
+		return "ISRC " + entry.ISRC
+	}
+	return entry.URI
+}
+
+// ReadFile will read the entries from the given file, the format is picked from the file extension
+func ReadFile(path string) ([]*Entry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return ReadCSV(file)
+	case ".m3u", ".m3u8":
+		return ReadM3U(file)
+	case ".json":
+		return ReadJSON(file)
+	}
+
+	return nil, errors.New("Unsupported file type, use .csv, .m3u or .json")
+}
+
+// TrackURI will return the spotify track URI from either a URI or an open.spotify.com link
+//
+// Returns an empty string if the given text is not a track
+func TrackURI(text string) string {
+	text = strings.TrimSpace(text)
+	if strings.HasPrefix(text, "spotify:track:") {
+		return text
+	}
+
+	for _, prefix := range []string{"https://open.spotify.com/track/", "http://open.spotify.com/track/"} {
+		if strings.HasPrefix(text, prefix) {
+			id := text[len(prefix):]
+			if end := strings.IndexAny(id, "?#/"); end >= 0 {
+				id = id[:end]
+			}
+			if id == "" {
+				return ""
+			}
+			return "spotify:track:" + id
+		}
+	}
+
+	return ""
+}
+
+// TrackID returns the spotify ID of the entry or an empty string if it has no spotify URI
+func (entry *Entry) TrackID() string {
+	uri := TrackURI(entry.URI)
+	if uri == "" {
+		return ""
+	}
+	return uri[len("spotify:track:"):]
+}